@@ -0,0 +1,200 @@
+package file
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Spec represents a batch of scripts to scaffold
+type Spec struct {
+	Scripts []SpecEntry `yaml:"scripts"`
+}
+
+// SpecEntry represents one script in a batch spec
+type SpecEntry struct {
+	Type         string              `yaml:"type"`
+	Name         string              `yaml:"name"`
+	Description  string              `yaml:"description"`
+	ScriptId     string              `yaml:"script_id"`
+	DeploymentId string              `yaml:"deployment_id"`
+	Instruct     string              `yaml:"instruct"`
+	ApiVersion   string              `yaml:"api_version"`
+	Entries      []string            `yaml:"entries"`
+	Deployments  []DeploymentOptions `yaml:"deployments"`
+	Params       []string            `yaml:"params"`
+}
+
+// CreateScript creates a script of the given type
+func (s *Tree) CreateScript(scriptType string, global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	switch scriptType {
+	case "bundle":
+		return s.CreateBundle(global, project, opts)
+	case "client":
+		return s.CreateClient(global, project, opts)
+	case "formclient":
+		return s.CreateFormClient(global, project, opts)
+	case "mapreduce":
+		return s.CreateMapReduce(global, project, opts)
+	case "massupdate":
+		return s.CreateMassUpdate(global, project, opts)
+	case "portlet":
+		return s.CreatePortlet(global, project, opts)
+	case "restlet":
+		return s.CreateRestlet(global, project, opts)
+	case "scheduled":
+		return s.CreateScheduled(global, project, opts)
+	case "suitelet":
+		return s.CreateSuitelet(global, project, opts)
+	case "userevent":
+		return s.CreateUserEvent(global, project, opts)
+	case "workflowaction":
+		return s.CreateWorkflowAction(global, project, opts)
+	case "module":
+		return s.CreateModule(global, project, opts)
+	case "type":
+		return s.CreateType(global, project, opts)
+	}
+	return fmt.Errorf("unknown script type %q", scriptType)
+}
+
+// CreateFromSpec creates every script listed in a YAML or JSON spec file.
+// Every entry is rendered into a single transaction and checked against the
+// tree and the other entries, so a single invalid entry leaves the tree
+// untouched.
+func (s *Tree) CreateFromSpec(global *store.GlobalStore, project *store.ProjectStore, path string) error {
+	// Read the spec file
+	spec, err := readSpec(path)
+	if err != nil {
+		return err
+	}
+	if len(spec.Scripts) == 0 {
+		return fmt.Errorf("no scripts found in %s", path)
+	}
+
+	// Render every entry in one transaction, committed only when all of them pass
+	headers := []string{"#", "TYPE", "NAME", "RESULT"}
+	rows := make([][]string, len(spec.Scripts))
+	err = s.atomically(func() error {
+		files := map[string]int{}
+		ids := map[string]int{}
+		failed := 0
+		for i, entry := range spec.Scripts {
+			mark := s.txn.mark()
+			err := s.createEntry(global, project, entry)
+			if err == nil {
+				err = checkEntry(s.txn.since(mark), files, ids, i)
+			}
+			result := "ok"
+			if err != nil {
+				// Drop the files of the failed entry so they do not clash with later ones
+				s.txn.rewind(mark)
+				result = err.Error()
+				failed++
+			}
+			rows[i] = []string{strconv.Itoa(i + 1), entry.Type, entry.Name, result}
+		}
+		// Refuse to write anything when an entry failed
+		if failed > 0 {
+			return fmt.Errorf("%d of %d entries failed validation, nothing was written", failed, len(spec.Scripts))
+		}
+		return nil
+	})
+	if err != nil {
+		util.PrintTable(headers, rows)
+		return err
+	}
+	for i := range rows {
+		rows[i][3] = "created"
+	}
+	util.PrintTable(headers, rows)
+	return nil
+}
+
+// createEntry runs the template pipeline of a spec entry
+func (s *Tree) createEntry(global *store.GlobalStore, project *store.ProjectStore, entry SpecEntry) error {
+	// Check the required values
+	if entry.Type == "" {
+		return fmt.Errorf("type must be set")
+	}
+	if entry.Name == "" {
+		return fmt.Errorf("name must be set")
+	}
+	return s.CreateScript(entry.Type, global, project, &ScriptOptions{
+		Name:         entry.Name,
+		Description:  entry.Description,
+		ScriptId:     entry.ScriptId,
+		DeploymentId: entry.DeploymentId,
+		Instruct:     entry.Instruct,
+		ApiVersion:   entry.ApiVersion,
+		Entries:      entry.Entries,
+		Deployments:  entry.Deployments,
+		Params:       entry.Params,
+		Yes:          true,
+	})
+}
+
+// checkEntry ensures the files written by an entry neither exist on disk nor
+// clash with the files or script ids of another entry
+func checkEntry(writes []txnWrite, files map[string]int, ids map[string]int, index int) error {
+	for _, w := range writes {
+		if w.staged == "" {
+			continue
+		}
+		// Check for a file already on disk
+		if util.Exists(w.destination) {
+			return fmt.Errorf("%s already exists", filepath.Base(w.destination))
+		}
+		// Check for a file produced by an earlier entry
+		if other, ok := files[w.destination]; ok {
+			return fmt.Errorf("%s is also created by entry %d", filepath.Base(w.destination), other+1)
+		}
+	}
+	// Check the script and deployment ids of the objects against the earlier entries
+	entryIds := map[string]bool{}
+	for _, w := range writes {
+		if w.staged == "" || filepath.Base(filepath.Dir(w.destination)) != "Objects" || filepath.Ext(w.destination) != ".xml" {
+			continue
+		}
+		object, err := readObject(w.staged)
+		if err != nil {
+			return err
+		}
+		for _, id := range object.ids {
+			if other, ok := ids[id.id]; ok {
+				return fmt.Errorf("%s is also used by entry %d", id.id, other+1)
+			}
+			entryIds[id.id] = true
+		}
+	}
+	// Record the files and ids once the whole entry passed
+	for _, w := range writes {
+		files[w.destination] = index
+	}
+	for id := range entryIds {
+		ids[id] = index
+	}
+	return nil
+}
+
+// readSpec reads a batch spec file, JSON being accepted as YAML
+func readSpec(path string) (*Spec, error) {
+	// Open the spec file
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	// Decode the spec, rejecting unknown keys to catch typos
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return spec, nil
+}
@@ -0,0 +1,104 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// CabinetPath is a path in the NetSuite FileCabinet, kept as its folder and
+// file names so it renders the same SDF reference on every platform
+type CabinetPath []string
+
+// NewCabinetPath returns the FileCabinet path made of the given names. Each
+// name must be a single folder or file, so vendor and project names can
+// neither add folders nor climb out of their parent.
+func NewCabinetPath(names ...string) (CabinetPath, error) {
+	return CabinetPath{}.Join(names...)
+}
+
+// ParseCabinetPath parses an SDF reference such as [/SuiteScripts/x.js],
+// accepting backslashes and a missing leading slash or brackets
+func ParseCabinetPath(reference string) CabinetPath {
+	reference = strings.TrimSpace(reference)
+	reference = strings.TrimSuffix(strings.TrimPrefix(reference, "["), "]")
+	var p CabinetPath
+	for _, name := range strings.FieldsFunc(reference, isSeparator) {
+		if name != "." {
+			p = append(p, name)
+		}
+	}
+	return p
+}
+
+// isSeparator checks if a character separates FileCabinet names
+func isSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// Join returns the path with the given names added, failing on a name that
+// is not a single folder or file
+func (p CabinetPath) Join(names ...string) (CabinetPath, error) {
+	joined := append(CabinetPath{}, p...)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		err := checkCabinetName(name)
+		if err != nil {
+			return nil, err
+		}
+		joined = append(joined, name)
+	}
+	return joined, nil
+}
+
+// checkCabinetName ensures a name is a single FileCabinet folder or file
+func checkCabinetName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("FileCabinet names must be non-empty")
+	case name == "." || name == "..":
+		return fmt.Errorf("%q is not a valid FileCabinet name", name)
+	case strings.ContainsFunc(name, isSeparator):
+		return fmt.Errorf("FileCabinet name %q must not contain / or \\", name)
+	}
+	return nil
+}
+
+// String returns the path with forward slashes, as in /SuiteScripts/x.js
+func (p CabinetPath) String() string {
+	return "/" + strings.Join(p, "/")
+}
+
+// Reference returns the path as an SDF file reference, as in [/SuiteScripts/x.js]
+func (p CabinetPath) Reference() string {
+	return "[" + p.String() + "]"
+}
+
+// Local returns the path of the file in the src/FileCabinet folder of a project root
+func (p CabinetPath) Local(root string) string {
+	return filepath.Join(append([]string{root, "src", "FileCabinet"}, p...)...)
+}
+
+// HasPrefix checks if the path is inside a folder
+func (p CabinetPath) HasPrefix(dir CabinetPath) bool {
+	if len(dir) > len(p) {
+		return false
+	}
+	for i, name := range dir {
+		if p[i] != name {
+			return false
+		}
+	}
+	return true
+}
+
+// WithExt returns the path with the extension of its file name replaced
+func (p CabinetPath) WithExt(ext string) CabinetPath {
+	if len(p) == 0 {
+		return p
+	}
+	replaced := append(CabinetPath{}, p...)
+	last := replaced[len(replaced)-1]
+	replaced[len(replaced)-1] = strings.TrimSuffix(last, filepath.Ext(last)) + ext
+	return replaced
+}
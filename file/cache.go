@@ -0,0 +1,98 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Inference cache modes
+const (
+	// cacheOn reads cached responses and stores new ones
+	cacheOn = "on"
+	// cacheOff always calls the provider and stores nothing
+	cacheOff = "off"
+	// cacheReplay only reads cached responses and fails when one is missing
+	cacheReplay = "replay"
+)
+
+// cachedResponse is an inference response stored in the cache
+type cachedResponse struct {
+	// Provider that answered
+	Provider string `json:"provider"`
+	// Model that answered
+	Model string `json:"model"`
+	// Time the response was stored
+	Created string `json:"created"`
+	// Raw model answer
+	Response string `json:"response"`
+}
+
+// cacheKey addresses a response by the provider, model, system prompt and template output
+func cacheKey(provider string, model string, system string, user string) string {
+	hash := sha256.New()
+	for _, part := range []string{provider, model, system, user} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// cacheMode returns the inference cache mode, turned off by the --no-cache option
+func cacheMode(mode string, opts *ScriptOptions) (string, error) {
+	if opts.NoCache {
+		return cacheOff, nil
+	}
+	switch mode {
+	case "":
+		return cacheOn, nil
+	case cacheOn, cacheOff, cacheReplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown inference cache mode %q, expected on, off or replay", mode)
+}
+
+// cachePath returns the path of a cached response in the project
+func (s *Tree) cachePath(key string) string {
+	return filepath.Join(s.dirname, ".nsc-cache", "inference", key+".json")
+}
+
+// readCache returns a cached response, reporting whether it was found
+func (s *Tree) readCache(key string) (string, bool, error) {
+	content, err := os.ReadFile(s.cachePath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	var cached cachedResponse
+	err = json.Unmarshal(content, &cached)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid cached response %s: %w", s.cachePath(key), err)
+	}
+	return cached.Response, true, nil
+}
+
+// writeCache stores a response in the cache
+func (s *Tree) writeCache(key string, provider string, model string, response string) error {
+	content, err := json.MarshalIndent(cachedResponse{
+		Provider: provider,
+		Model:    model,
+		Created:  time.Now().Format(time.RFC3339),
+		Response: response,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.cachePath(key)), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.cachePath(key), content, 0644)
+}
@@ -0,0 +1,126 @@
+package file
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"netsuite-companion/store"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testSecrets holds a fixed API key
+type testSecrets struct {
+}
+
+// Get returns a test API key
+func (testSecrets) Get(name string) (string, error) {
+	return "sk-test", nil
+}
+
+// Set ignores the secret
+func (testSecrets) Set(name string, value string) error {
+	return nil
+}
+
+// newCacheTest creates a tree and a provider server counting its calls
+func newCacheTest(t *testing.T, mode string) (*Tree, *store.GlobalStore, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"answer"}}]}`))
+	}))
+	t.Cleanup(server.Close)
+	tree := CreateTree(t.TempDir())
+	tree.SetSecrets(testSecrets{})
+	global := &store.GlobalStore{
+		InferenceProvider: "compatible",
+		InferenceModel:    "test-model",
+		InferenceBaseURL:  server.URL,
+		InferenceCache:    mode,
+	}
+	return tree, global, &calls
+}
+
+func TestCacheMiss(t *testing.T) {
+	tree, global, calls := newCacheTest(t, cacheOn)
+	answer, err := tree.runInference(global, &ScriptOptions{}, "system", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "answer" || *calls != 1 {
+		t.Fatalf("got %q after %d calls, want answer after 1", answer, *calls)
+	}
+	// The response is stored under its key
+	key := cacheKey("compatible", "test-model", "system", "template")
+	if _, err := os.Stat(tree.cachePath(key)); err != nil {
+		t.Fatalf("response not cached: %v", err)
+	}
+}
+
+func TestCacheHit(t *testing.T) {
+	tree, global, calls := newCacheTest(t, cacheOn)
+	for i := 0; i < 2; i++ {
+		answer, err := tree.runInference(global, &ScriptOptions{}, "system", "template")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if answer != "answer" {
+			t.Fatalf("got %q, want answer", answer)
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("provider called %d times, want 1", *calls)
+	}
+	// Another prompt misses the cache
+	_, err := tree.runInference(global, &ScriptOptions{}, "other system", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 2 {
+		t.Fatalf("provider called %d times, want 2", *calls)
+	}
+}
+
+func TestCacheReplay(t *testing.T) {
+	tree, global, calls := newCacheTest(t, cacheReplay)
+	// Replay never creates a provider, so no API key is needed
+	tree.SetSecrets(nil)
+	// A missing response fails
+	_, err := tree.runInference(global, &ScriptOptions{}, "system", "template")
+	if err == nil {
+		t.Fatal("expected an error for a missing response in replay mode")
+	}
+	// A stored response is replayed
+	key := cacheKey("compatible", "test-model", "system", "template")
+	err = tree.writeCache(key, "compatible", "test-model", "replayed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := tree.runInference(global, &ScriptOptions{}, "system", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "replayed" || *calls != 0 {
+		t.Fatalf("got %q after %d calls, want replayed after 0", answer, *calls)
+	}
+}
+
+func TestCacheOff(t *testing.T) {
+	tree, global, calls := newCacheTest(t, cacheOn)
+	for i := 0; i < 2; i++ {
+		_, err := tree.runInference(global, &ScriptOptions{NoCache: true}, "system", "template")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if *calls != 2 {
+		t.Fatalf("provider called %d times, want 2", *calls)
+	}
+	// Nothing is stored
+	if _, err := os.Stat(filepath.Join(tree.dirname, ".nsc-cache")); !os.IsNotExist(err) {
+		t.Fatalf("cache written with --no-cache: %v", err)
+	}
+}
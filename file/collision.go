@@ -0,0 +1,156 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ways to resolve a collision with existing files or ids
+const (
+	collisionSkip      = "skip"
+	collisionOverwrite = "overwrite"
+	collisionRename    = "rename"
+)
+
+// scriptIdPattern matches the script ids declared in an object file
+var scriptIdPattern = regexp.MustCompile(`scriptid="([^"]+)"`)
+
+// collision lists what a new script would clash with
+type collision struct {
+	// Existing files the script would replace
+	files []string
+	// Ids already used by another object, mapped to that object file
+	ids map[string]string
+}
+
+// describe returns one line per clash, relative to the project root
+func (c *collision) describe(root string) []string {
+	var lines []string
+	for _, path := range c.files {
+		lines = append(lines, fmt.Sprintf("%s already exists", relPath(root, path)))
+	}
+	ids := make([]string, 0, len(c.ids))
+	for id := range c.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		lines = append(lines, fmt.Sprintf("%s is already used by %s", id, relPath(root, c.ids[id])))
+	}
+	return lines
+}
+
+// relPath returns a path relative to the project root when possible
+func relPath(root string, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// objectIds returns every script id declared in the object files, mapped to
+// their file, leaving out the files in skip
+func (s *Tree) objectIds(skip ...string) (map[string]string, error) {
+	ids := map[string]string{}
+	paths, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if slices.Contains(skip, path) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range scriptIdPattern.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := ids[match[1]]; !ok {
+				ids[match[1]] = path
+			}
+		}
+	}
+	return ids, nil
+}
+
+// checkCollisions returns what the files and ids of a new script clash
+// with, or nil when they are free
+func (s *Tree) checkCollisions(paths []string, ids []string) (*collision, error) {
+	c := &collision{ids: map[string]string{}}
+	for _, path := range paths {
+		if path != "" && util.Exists(path) {
+			c.files = append(c.files, path)
+		}
+	}
+	// Ids in the files being replaced do not count
+	used, err := s.objectIds(paths...)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if path, ok := used[id]; ok {
+			c.ids[id] = path
+		}
+	}
+	if len(c.files) == 0 && len(c.ids) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// resolveCollision decides how to go on with a collision: --force
+// overwrites, otherwise the user picks between skipping, overwriting with a
+// backup and choosing a new name. Ids used by other objects cannot be
+// overwritten.
+func (s *Tree) resolveCollision(c *collision, opts *ScriptOptions) (string, error) {
+	lines := c.describe(s.dirname)
+	message := strings.Join(lines, "\n  ")
+	// Refuse to reuse the ids of another object
+	if opts.Force || opts.Yes || !util.IsInteractive() {
+		if len(c.ids) > 0 {
+			return "", fmt.Errorf("%s\n  set --name, --script-id or --deployment-id to use other ids", message)
+		}
+		if !opts.Force {
+			return "", fmt.Errorf("%s\n  use --force to overwrite them", message)
+		}
+		return collisionOverwrite, nil
+	}
+	// Ask the user, only offering to overwrite files
+	fmt.Printf("The new script clashes with existing work:\n  %s\n", message)
+	options := []string{collisionSkip, collisionRename}
+	if len(c.ids) == 0 {
+		options = []string{collisionSkip, collisionOverwrite, collisionRename}
+	}
+	return util.Choose("How do you want to go on?", options)
+}
+
+// backupFiles copies files to a timestamped folder under .nsc-backup at the
+// project root, keeping their path relative to the root
+func (s *Tree) backupFiles(paths ...string) error {
+	dir := filepath.Join(s.dirname, ".nsc-backup", time.Now().Format("20060102-150405"))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		backup := filepath.Join(dir, relPath(s.dirname, path))
+		err = os.MkdirAll(filepath.Dir(backup), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(backup, content, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %s to %s\n", relPath(s.dirname, path), relPath(s.dirname, backup))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// contextFile is a project file offered to the model as context
+type contextFile struct {
+	// Path relative to the project root
+	Path string
+	// File content
+	Content string
+	// Estimated token count
+	Tokens int
+}
+
+// estimateTokens estimates the token count of a text at four characters per token
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// contextCandidates lists the files that can be sent as context, most useful first:
+// files named with --context-file, type files, field constants, record accessors,
+// modules and the custom record and field objects
+func (s *Tree) contextCandidates(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) ([]string, error) {
+	var paths []string
+	// Add the files named in the options
+	for _, path := range opts.ContextFiles {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.dirname, path)
+		}
+		if !util.Exists(path) {
+			return nil, fmt.Errorf("context file %s does not exist", path)
+		}
+		paths = append(paths, path)
+	}
+	if !opts.Context {
+		return paths, nil
+	}
+	// Add the project TypeScript files by kind
+	projectFolder, err := projectPath(global, project)
+	if err != nil {
+		return nil, err
+	}
+	folder := projectFolder.Local(s.dirname)
+	for _, pattern := range []string{"*_type.ts", "fields.ts", "*_record.ts", "*_module.ts"} {
+		matches, err := filepath.Glob(filepath.Join(folder, pattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	// Add the custom record and field objects
+	matches, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "cust*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return append(paths, matches...), nil
+}
+
+// projectContext gathers project files under the configured token budget and
+// returns them as a prompt section. A summary of what was sent is printed.
+func (s *Tree) projectContext(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) (string, error) {
+	if !opts.Context && len(opts.ContextFiles) == 0 {
+		return "", nil
+	}
+	paths, err := s.contextCandidates(global, project, opts)
+	if err != nil {
+		return "", err
+	}
+	// Add files in order while they fit in the budget
+	var sent []contextFile
+	var rows [][]string
+	seen := map[string]bool{}
+	used := 0
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(s.dirname, path)
+		if err != nil {
+			rel = path
+		}
+		file := contextFile{Path: filepath.ToSlash(rel), Content: string(content), Tokens: estimateTokens(string(content))}
+		status := "sent"
+		if used+file.Tokens > global.InferenceContextTokens {
+			status = "skipped, over budget"
+		} else {
+			used += file.Tokens
+			sent = append(sent, file)
+		}
+		rows = append(rows, []string{file.Path, fmt.Sprint(file.Tokens), status})
+	}
+
+	// Print the summary
+	fmt.Printf("Inference context: %d of %d files, ~%d of %d tokens\n", len(sent), len(rows), used, global.InferenceContextTokens)
+	if len(rows) > 0 {
+		util.PrintTable([]string{"FILE", "TOKENS", "STATUS"}, rows)
+	}
+	if len(sent) == 0 {
+		return "", nil
+	}
+
+	// Build the prompt section
+	var b strings.Builder
+	b.WriteString("The project already has the following files. Reuse its modules, types, field ids and records where they fit.\n")
+	for _, file := range sent {
+		b.WriteString(fmt.Sprintf("\n// File: %s\n%s\n", file.Path, strings.TrimRight(file.Content, "\n")))
+	}
+	return b.String(), nil
+}
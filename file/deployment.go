@@ -0,0 +1,336 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/util"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Deployment represents a scriptdeployment of a script object
+type Deployment struct {
+	// Deployment script ID
+	ScriptId string
+	// Deployment title
+	Title string
+	// Record type the script is deployed to
+	RecordType string
+	// Whether every employee is in the audience
+	AllEmployees bool
+	// Whether every role is in the audience
+	AllRoles bool
+	// Roles in the audience
+	Roles []string
+	// Departments in the audience
+	Departments []string
+	// Subsidiaries in the audience
+	Subsidiaries []string
+	// Log level
+	LogLevel string
+	// Deployment status
+	Status string
+	// Role the script executes as
+	RunAsRole string
+	// Event type the script runs on
+	EventType string
+}
+
+// AudienceRoles returns the audience roles as an SDF multi-select value
+func (d *Deployment) AudienceRoles() string {
+	return strings.Join(d.Roles, "|")
+}
+
+// AudienceDepartments returns the audience departments as an SDF multi-select value
+func (d *Deployment) AudienceDepartments() string {
+	return strings.Join(d.Departments, "|")
+}
+
+// AudienceSubsidiaries returns the audience subsidiaries as an SDF multi-select value
+func (d *Deployment) AudienceSubsidiaries() string {
+	return strings.Join(d.Subsidiaries, "|")
+}
+
+// DeploymentOptions holds the settings of one or more deployments, one per record type
+type DeploymentOptions struct {
+	// Record types to deploy to, one deployment each
+	RecordTypes []string `yaml:"record_types"`
+	// Audience roles, or "all" for every role
+	Roles []string `yaml:"roles"`
+	// Audience departments
+	Departments []string `yaml:"departments"`
+	// Audience subsidiaries
+	Subsidiaries []string `yaml:"subsidiaries"`
+	// Log level
+	LogLevel string `yaml:"log_level"`
+	// Deployment status
+	Status string `yaml:"status"`
+	// Role the script executes as
+	RunAsRole string `yaml:"run_as_role"`
+	// Event type the script runs on
+	EventType string `yaml:"event_type"`
+}
+
+// Empty checks if no deployment setting is set
+func (o DeploymentOptions) Empty() bool {
+	return len(o.RecordTypes) == 0 && len(o.Roles) == 0 && len(o.Departments) == 0 && len(o.Subsidiaries) == 0 &&
+		o.LogLevel == "" && o.Status == "" && o.RunAsRole == "" && o.EventType == ""
+}
+
+// deploymentSupport describes the deployment settings a script type supports
+type deploymentSupport struct {
+	// Whether deployments are made per record type
+	recordType bool
+	// Whether the audience can be set
+	audience bool
+	// Whether the execute-as role can be set
+	runAsRole bool
+	// Whether the event type can be set
+	eventType bool
+	// Valid statuses
+	statuses []string
+	// Default settings of a deployment
+	defaults Deployment
+}
+
+// Statuses of script deployments
+var (
+	releaseStatuses  = []string{"RELEASED", "TESTING"}
+	scheduleStatuses = []string{"NOTSCHEDULED", "SCHEDULED", "TESTING"}
+)
+
+// deploymentTypes lists the deployment settings of each script type with deployments
+var deploymentTypes = map[string]deploymentSupport{
+	"bundle": {
+		runAsRole: true,
+		statuses:  releaseStatuses,
+		defaults:  Deployment{LogLevel: "DEBUG", Status: "RELEASED", RunAsRole: "ADMINISTRATOR"},
+	},
+	"client": {
+		recordType: true,
+		audience:   true,
+		statuses:   releaseStatuses,
+		defaults:   Deployment{AllRoles: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+	"mapreduce": {
+		statuses: scheduleStatuses,
+		defaults: Deployment{LogLevel: "DEBUG", Status: "NOTSCHEDULED"},
+	},
+	"massupdate": {
+		recordType: true,
+		audience:   true,
+		runAsRole:  true,
+		statuses:   releaseStatuses,
+		defaults:   Deployment{AllRoles: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+	"portlet": {
+		audience:  true,
+		runAsRole: true,
+		statuses:  releaseStatuses,
+		defaults:  Deployment{AllEmployees: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+	"restlet": {
+		audience: true,
+		statuses: releaseStatuses,
+		defaults: Deployment{AllEmployees: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+	"scheduled": {
+		statuses: scheduleStatuses,
+		defaults: Deployment{LogLevel: "DEBUG", Status: "NOTSCHEDULED"},
+	},
+	"suitelet": {
+		audience:  true,
+		runAsRole: true,
+		statuses:  releaseStatuses,
+		defaults:  Deployment{AllEmployees: true, LogLevel: "ERROR", Status: "RELEASED", RunAsRole: "ADMINISTRATOR"},
+	},
+	"userevent": {
+		recordType: true,
+		audience:   true,
+		runAsRole:  true,
+		eventType:  true,
+		statuses:   releaseStatuses,
+		defaults:   Deployment{AllRoles: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+	"workflowaction": {
+		recordType: true,
+		audience:   true,
+		runAsRole:  true,
+		statuses:   releaseStatuses,
+		defaults:   Deployment{AllRoles: true, LogLevel: "ERROR", Status: "RELEASED"},
+	},
+}
+
+// logLevels lists the deployment log levels
+var logLevels = []string{"DEBUG", "AUDIT", "ERROR", "EMERGENCY"}
+
+// eventTypes lists the user event types a deployment can be limited to
+var eventTypes = []string{
+	"APPROVE", "CANCEL", "CHANGEPASSWORD", "COPY", "CREATE", "DELETE", "DROPSHIP", "EDIT", "EDITFORECAST",
+	"EMAIL", "MARKCOMPLETE", "ORDERITEMS", "PACK", "PAYBILLS", "PRINT", "QUICKVIEW", "REASSIGN", "REJECT",
+	"SHIP", "SPECIALORDER", "TRANSFORM", "VIEW", "XEDIT",
+}
+
+// sdfValue returns a value as SDF expects it: standard ids upper case and
+// custom object ids as a [scriptid] reference
+func sdfValue(value string) string {
+	value = strings.TrimSpace(value)
+	if isCustomObjectId(value) {
+		return "[" + strings.Trim(strings.ToLower(value), "[]") + "]"
+	}
+	return strings.ToUpper(value)
+}
+
+// sdfValues returns a list of values as SDF expects them
+func sdfValues(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			result = append(result, sdfValue(value))
+		}
+	}
+	return result
+}
+
+// deploymentOptions returns the deployment options of a script, asking for
+// the record types of record level scripts when none are given. No options
+// means the script gets no deployment.
+func deploymentOptions(scriptType string, opts *ScriptOptions) ([]DeploymentOptions, error) {
+	support, ok := deploymentTypes[scriptType]
+	if !ok {
+		for _, o := range opts.Deployments {
+			if !o.Empty() {
+				return nil, fmt.Errorf("%s files have no deployments", scriptType)
+			}
+		}
+		return nil, nil
+	}
+
+	// Use a single default deployment when none is configured
+	options := opts.Deployments
+	if len(options) == 0 {
+		options = []DeploymentOptions{{}}
+	}
+	// Ask for the record types of record level scripts
+	if support.recordType && len(options) == 1 && len(options[0].RecordTypes) == 0 {
+		if opts.Yes || !util.IsInteractive() {
+			// Deployment settings without a record type cannot be deployed
+			if !options[0].Empty() {
+				return nil, fmt.Errorf("%s deployments need a record type, set --record-type", scriptType)
+			}
+			return nil, nil
+		}
+		input := util.GetInput("Enter the record types to deploy to, separated by commas (empty for none): ")
+		options[0].RecordTypes = strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(options[0].RecordTypes) == 0 {
+			return nil, nil
+		}
+	}
+	return options, nil
+}
+
+// buildDeployments returns the deployments of a script from its deployment options
+func buildDeployments(scriptType string, options []DeploymentOptions, deploymentId string, title string) ([]*Deployment, error) {
+	support, ok := deploymentTypes[scriptType]
+	if !ok || len(options) == 0 {
+		return nil, nil
+	}
+
+	// Build a deployment for each option and record type
+	var deployments []*Deployment
+	for _, o := range options {
+		recordTypes := sdfValues(o.RecordTypes)
+		if support.recordType && len(recordTypes) == 0 {
+			return nil, fmt.Errorf("%s deployments need a record type", scriptType)
+		}
+		if !support.recordType && len(recordTypes) > 0 {
+			return nil, fmt.Errorf("%s deployments have no record type", scriptType)
+		}
+		if len(recordTypes) == 0 {
+			recordTypes = []string{""}
+		}
+		for _, recordType := range recordTypes {
+			d, err := buildDeployment(scriptType, support, o)
+			if err != nil {
+				return nil, err
+			}
+			d.RecordType = recordType
+			deployments = append(deployments, d)
+		}
+	}
+
+	// Name the deployments, suffixing ids and titles when there are several
+	seen := map[string]bool{}
+	for i, d := range deployments {
+		d.ScriptId, d.Title = deploymentId, title
+		if len(deployments) > 1 {
+			suffix := strconv.Itoa(i + 1)
+			if d.RecordType != "" {
+				suffix = strings.ToLower(strings.Trim(d.RecordType, "[]"))
+			}
+			d.ScriptId = deploymentId + "_" + suffix
+			d.Title = fmt.Sprintf("%s %s", title, suffix)
+		}
+		if seen[d.ScriptId] {
+			return nil, fmt.Errorf("deployment %s is set more than once", d.ScriptId)
+		}
+		seen[d.ScriptId] = true
+	}
+	return deployments, nil
+}
+
+// buildDeployment returns a deployment with the defaults of the script type and the options applied
+func buildDeployment(scriptType string, support deploymentSupport, o DeploymentOptions) (*Deployment, error) {
+	d := support.defaults
+	// Set the audience
+	if len(o.Roles) > 0 || len(o.Departments) > 0 || len(o.Subsidiaries) > 0 {
+		if !support.audience {
+			return nil, fmt.Errorf("%s deployments have no audience", scriptType)
+		}
+	}
+	if len(o.Roles) > 0 {
+		d.AllEmployees, d.AllRoles = false, false
+		for _, role := range o.Roles {
+			if strings.EqualFold(role, "all") {
+				d.AllRoles = true
+			}
+		}
+		if !d.AllRoles {
+			d.Roles = sdfValues(o.Roles)
+		}
+	}
+	d.Departments = sdfValues(o.Departments)
+	d.Subsidiaries = sdfValues(o.Subsidiaries)
+	// Set the log level
+	if o.LogLevel != "" {
+		d.LogLevel = strings.ToUpper(o.LogLevel)
+		if !slices.Contains(logLevels, d.LogLevel) {
+			return nil, fmt.Errorf("unknown log level %q, expected one of %s", o.LogLevel, strings.Join(logLevels, ", "))
+		}
+	}
+	// Set the status
+	if o.Status != "" {
+		d.Status = strings.ToUpper(o.Status)
+		if !slices.Contains(support.statuses, d.Status) {
+			return nil, fmt.Errorf("unknown %s deployment status %q, expected one of %s", scriptType, o.Status, strings.Join(support.statuses, ", "))
+		}
+	}
+	// Set the execute-as role
+	if o.RunAsRole != "" {
+		if !support.runAsRole {
+			return nil, fmt.Errorf("%s deployments have no execute-as role", scriptType)
+		}
+		d.RunAsRole = sdfValue(o.RunAsRole)
+	}
+	// Set the event type
+	if o.EventType != "" {
+		if !support.eventType {
+			return nil, fmt.Errorf("%s deployments have no event type", scriptType)
+		}
+		d.EventType = strings.ToUpper(o.EventType)
+		if !slices.Contains(eventTypes, d.EventType) {
+			return nil, fmt.Errorf("unknown event type %q, expected one of %s", o.EventType, strings.Join(eventTypes, ", "))
+		}
+	}
+	return &d, nil
+}
@@ -0,0 +1,99 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+)
+
+// resolveScript finds an existing script from the working directory, the
+// project root or the current project folder
+func (s *Tree) resolveScript(global *store.GlobalStore, project *store.ProjectStore, name string) (string, error) {
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		folder, err := projectPath(global, project)
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates,
+			filepath.Join(s.dirname, name),
+			filepath.Join(folder.Local(s.dirname), name),
+		)
+	}
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("file %s does not exist", name)
+}
+
+// EditScript revises an existing script with the inference provider. The
+// proposed change is shown as a unified diff and applied on confirmation,
+// keeping the previous version in .nsc-backup.
+func (s *Tree) EditScript(global *store.GlobalStore, project *store.ProjectStore, name string, opts *ScriptOptions) error {
+	// Find and read the script
+	path, err := s.resolveScript(global, project, name)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	original := string(content)
+
+	// Get the instructions and the project context
+	instruct, err := opts.instructions()
+	if err != nil {
+		return err
+	}
+	context, err := s.projectContext(global, project, opts)
+	if err != nil {
+		return err
+	}
+	if context != "" {
+		instruct = instruct + "\n\n" + context
+	}
+
+	// Run the inference, checking the output keeps the tags and entry points of the script
+	edited, err := s.runCheckedInference(global, opts, instruct, original)
+	if err != nil {
+		return err
+	}
+	if edited == original {
+		fmt.Println("No changes proposed")
+		return nil
+	}
+
+	// Show the change and ask before applying it
+	rel, err := filepath.Rel(s.dirname, path)
+	if err != nil {
+		rel = path
+	}
+	fmt.Print(util.UnifiedDiff(filepath.ToSlash(rel), original, edited))
+	ok, err := util.Confirm("Apply the change?", opts.Yes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errDeclined
+	}
+
+	// Keep a backup and write the change
+	err = s.backupFiles(path)
+	if err != nil {
+		return err
+	}
+	err = s.atomically(func() error {
+		return s.createFile(path, edited)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", rel)
+	return nil
+}
@@ -0,0 +1,373 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CustomField represents a custom body, entity, column or item field
+type CustomField struct {
+	// Company name
+	CompanyName string
+	// Date of field creation
+	Date string
+	// Project name
+	Project string
+	// User email
+	UserEmail string
+	// User name
+	UserName string
+	// SDF object type
+	ObjectType string
+	// Field script ID
+	ScriptId string
+	// Field label
+	Label string
+	// Description of field
+	Description string
+	// SDF field type
+	Type string
+	// SDF display type
+	DisplayType string
+	// Field help text
+	Help string
+	// Whether the field is mandatory
+	Mandatory bool
+	// Source list or record for select fields
+	Source string
+	// Records the field applies to
+	AppliesTo []AppliesTo
+	// SuiteScript API version
+	ApiVersion string
+}
+
+// AppliesTo represents an applies-to flag of a custom field
+type AppliesTo struct {
+	// XML tag of the flag
+	Tag string
+	// Whether the flag is set
+	Enabled bool
+}
+
+// FieldOptions holds values for a custom field that would otherwise be prompted for
+type FieldOptions struct {
+	// Field id, with or without the kind and vendor prefixes
+	Id string
+	// Field label
+	Label string
+	// Description of field
+	Description string
+	// Field type
+	Type string
+	// Display type
+	DisplayType string
+	// Field help text
+	Help string
+	// Source list or record for select fields
+	Source string
+	// Records the field applies to
+	AppliesTo []string
+	// Whether the field is mandatory
+	Mandatory bool
+	// Use defaults instead of prompting
+	Yes bool
+}
+
+// fieldKind describes one kind of custom field
+type fieldKind struct {
+	// SDF object type
+	objectType string
+	// Script id prefix
+	prefix string
+	// Applies-to options mapped to their XML tags
+	appliesTo map[string]string
+	// Applies-to options enabled when none are given
+	defaults []string
+}
+
+// fieldKinds maps each field kind to its SDF object description
+var fieldKinds = map[string]fieldKind{
+	"body": {
+		objectType: "transactionbodycustomfield",
+		prefix:     "custbody_",
+		appliesTo: map[string]string{
+			"sale":                "bodysale",
+			"purchase":            "bodypurchase",
+			"journal":             "bodyjournal",
+			"expensereport":       "bodyexpensereport",
+			"opportunity":         "bodyopportunity",
+			"itemfulfillment":     "bodyitemfulfillment",
+			"itemreceipt":         "bodyitemreceipt",
+			"inventoryadjustment": "bodyinventoryadjustment",
+			"customerpayment":     "bodycustomerpayment",
+			"vendorpayment":       "bodyvendorpayment",
+		},
+		defaults: []string{"sale"},
+	},
+	"column": {
+		objectType: "transactioncolumncustomfield",
+		prefix:     "custcol_",
+		appliesTo: map[string]string{
+			"sale":            "colsale",
+			"purchase":        "colpurchase",
+			"journal":         "coljournal",
+			"expensereport":   "colexpensereport",
+			"opportunity":     "colopportunity",
+			"itemfulfillment": "colitemfulfillment",
+			"itemreceipt":     "colitemreceipt",
+			"transferorder":   "coltransferorder",
+		},
+		defaults: []string{"sale"},
+	},
+	"entity": {
+		objectType: "entitycustomfield",
+		prefix:     "custentity_",
+		appliesTo: map[string]string{
+			"customer": "appliestocustomer",
+			"vendor":   "appliestovendor",
+			"employee": "appliestoemployee",
+			"contact":  "appliestocontact",
+			"partner":  "appliestopartner",
+			"project":  "appliestoproject",
+		},
+		defaults: []string{"customer"},
+	},
+	"item": {
+		objectType: "itemcustomfield",
+		prefix:     "custitem_",
+		appliesTo: map[string]string{
+			"inventory":    "appliestoinventory",
+			"noninventory": "appliestononinventory",
+			"service":      "appliestoservice",
+			"othercharge":  "appliestoothercharge",
+			"kit":          "appliestokit",
+			"group":        "appliestogroup",
+			"assembly":     "appliestoitemassembly",
+		},
+		defaults: []string{"inventory"},
+	},
+}
+
+// displayTypes lists the valid field display types
+var displayTypes = []string{"NORMAL", "DISABLED", "HIDDEN", "INLINE", "LOCKED", "SHOWASLIST", "STATICTEXT"}
+
+// FieldKinds returns every custom field kind
+func FieldKinds() []string {
+	kinds := make([]string, 0, len(fieldKinds))
+	for kind := range fieldKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// FieldAppliesTo returns the applies-to options of a field kind
+func FieldAppliesTo(kind string) []string {
+	var options []string
+	for option := range fieldKinds[kind].appliesTo {
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	return options
+}
+
+// CreateField creates a custom field object and adds its id to the project fields module
+func (s *Tree) CreateField(global *store.GlobalStore, project *store.ProjectStore, kind string, opts *FieldOptions) error {
+	// Get the field kind
+	fk, ok := fieldKinds[kind]
+	if !ok {
+		return fmt.Errorf("unknown field kind %q", kind)
+	}
+
+	// Get the field id and label from the options or the user
+	id, err := util.AskInput(opts.Id, "Enter the field id: ", "id", "", opts.Yes)
+	if err != nil {
+		return err
+	}
+	scriptId, err := fieldScriptId(global, fk.prefix, id)
+	if err != nil {
+		return err
+	}
+	label, err := util.AskInput(opts.Label, "Enter the field label: ", "label", "", opts.Yes)
+	if err != nil {
+		return err
+	}
+	// Get the field type and source, defaulting to a text field
+	fieldType, err := util.AskInput(opts.Type, "Enter the field type (default text): ", "type", "text", opts.Yes)
+	if err != nil {
+		return err
+	}
+	source := opts.Source
+	if source == "" && strings.HasSuffix(strings.ToUpper(fieldType), "SELECT") && !opts.Yes && util.IsInteractive() {
+		source = util.GetInput("Enter the list or record source: ")
+	}
+	fieldType, source, err = checkFieldType(scriptId, fieldType, source)
+	if err != nil {
+		return err
+	}
+	// Check the display type
+	displayType := strings.ToUpper(opts.DisplayType)
+	if displayType == "" {
+		displayType = "NORMAL"
+	}
+	if !slices.Contains(displayTypes, displayType) {
+		return fmt.Errorf("unknown display type %q, expected one of %s", opts.DisplayType, strings.Join(displayTypes, ", "))
+	}
+	// Resolve the applies-to flags
+	appliesTo, err := fieldAppliesTo(fk, opts.AppliesTo)
+	if err != nil {
+		return err
+	}
+
+	// Get the API version of the project
+	version, err := apiVersion(project, "")
+	if err != nil {
+		return err
+	}
+	// Create a new custom field
+	field := &CustomField{
+		CompanyName: global.VendorName,
+		Date:        time.Now().Format("01/02/2006"),
+		Project:     project.Current,
+		UserEmail:   global.AuthorEmail,
+		UserName:    global.AuthorName,
+		ObjectType:  fk.objectType,
+		ScriptId:    scriptId,
+		Label:       label,
+		Description: opts.Description,
+		Type:        fieldType,
+		DisplayType: displayType,
+		Help:        opts.Help,
+		Mandatory:   opts.Mandatory,
+		Source:      source,
+		ApiVersion:  version,
+		AppliesTo:   appliesTo,
+	}
+
+	// Add the field id to the fields module before writing anything
+	folder, err := projectPath(global, project)
+	if err != nil {
+		return err
+	}
+	fieldsCabinetPath, err := folder.Join("fields.ts")
+	if err != nil {
+		return err
+	}
+	fieldsPath := fieldsCabinetPath.Local(s.dirname)
+	fieldsTS, err := s.addFieldConstant(fieldsPath, field)
+	if err != nil {
+		return err
+	}
+
+	// Look up, parse and create the xml object
+	xml, _, err := s.lookupTemplate("field.xml")
+	if err != nil {
+		return err
+	}
+	parsedXML, err := s.parseTemplate(field, "field", xml)
+	if err != nil {
+		return err
+	}
+	// Create the object and update the fields module together
+	return s.atomically(func() error {
+		err := s.createFile(filepath.Join(s.dirname, "src", "Objects", scriptId+".xml"), parsedXML)
+		if err != nil {
+			return err
+		}
+		return s.createFile(fieldsPath, fieldsTS)
+	})
+}
+
+// addFieldConstant returns the fields module at path with a constant holding the field id appended
+func (s *Tree) addFieldConstant(path string, field *CustomField) (string, error) {
+	name := strings.ToUpper(field.ScriptId)
+
+	// Read the current module, or start from its header
+	var content string
+	current, err := os.ReadFile(path)
+	if err == nil {
+		content = string(current)
+	} else if os.IsNotExist(err) {
+		header, _, err := s.lookupTemplate("fields.ts")
+		if err != nil {
+			return "", err
+		}
+		content, err = s.parseTemplate(field, "fields", header)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		return "", err
+	}
+
+	// Refuse to declare the same constant twice
+	if strings.Contains(content, fmt.Sprintf("export const %s ", name)) {
+		return "", fmt.Errorf("%s is already declared in %s", name, path)
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += fmt.Sprintf("\n/** %s */\nexport const %s = \"%s\";\n", docComment(field.Label), name, field.ScriptId)
+	return content, nil
+}
+
+// fieldIdPattern matches the characters SDF accepts in a field script id
+var fieldIdPattern = regexp.MustCompile(`^[a-z0-9_]*$`)
+
+// fieldScriptId builds the field script id, adding the kind and vendor
+// prefixes to bare ids and ensuring full ids carry the vendor prefix
+func fieldScriptId(global *store.GlobalStore, prefix string, id string) (string, error) {
+	// Normalize the id
+	id = strings.ReplaceAll(strings.ToLower(id), " ", "_")
+	if !fieldIdPattern.MatchString(id) {
+		return "", fmt.Errorf("field id %q may only contain a-z, 0-9 and _", id)
+	}
+	vendor := strings.ToLower(global.VendorPrefix) + "_"
+	// Check full ids against the vendor prefix
+	if strings.HasPrefix(id, prefix) {
+		if !strings.HasPrefix(strings.TrimPrefix(id, prefix), vendor) || len(id) == len(prefix+vendor) {
+			return "", fmt.Errorf("field id %s must start with %s%s", id, prefix, vendor)
+		}
+		return id, nil
+	}
+	// Add the prefixes to bare ids
+	id = strings.TrimPrefix(id, vendor)
+	if id == "" {
+		return "", fmt.Errorf("field id must be non-empty")
+	}
+	return prefix + vendor + id, nil
+}
+
+// fieldAppliesTo returns every applies-to flag of a field kind, enabling the
+// selected options or the kind defaults when none are selected
+func fieldAppliesTo(fk fieldKind, selected []string) ([]AppliesTo, error) {
+	// Use the defaults when nothing was selected
+	if len(selected) == 0 {
+		selected = fk.defaults
+	}
+	enabled := map[string]bool{}
+	for _, option := range selected {
+		tag, ok := fk.appliesTo[strings.ToLower(option)]
+		if !ok {
+			return nil, fmt.Errorf("unknown applies-to %q for %s", option, fk.objectType)
+		}
+		enabled[tag] = true
+	}
+	// List every flag in tag order
+	var appliesTo []AppliesTo
+	for _, tag := range fk.appliesTo {
+		appliesTo = append(appliesTo, AppliesTo{Tag: tag, Enabled: enabled[tag]})
+	}
+	sort.Slice(appliesTo, func(i, j int) bool {
+		return appliesTo[i].Tag < appliesTo[j].Tag
+	})
+	return appliesTo, nil
+}
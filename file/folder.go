@@ -2,29 +2,251 @@ package file
 
 import (
 	"fmt"
+	"io/fs"
 	"netsuite-companion/store"
+	"netsuite-companion/util"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
-// CreateManifest creates a manifest file for a NetSuite project
+// CreateManifest creates a manifest file for the current NetSuite project
 func (s *Tree) CreateManifest(project *store.ProjectStore) error {
-	err := s.createFile("src/manifest.xml", fmt.Sprintf(`<manifest projecttype="ACCOUNTCUSTOMIZATION">
-  <projectname>{{%s}}</projectname>
+	return s.atomically(func() error {
+		return s.createFile(filepath.Join(s.dirname, "src", "manifest.xml"), fmt.Sprintf(`<manifest projecttype="ACCOUNTCUSTOMIZATION">
+  <projectname>%s</projectname>
   <frameworkversion>1.0</frameworkversion>
 </manifest>
-`, project.Current))
+`, escapeXML(project.Current)))
+	})
+}
+
+// CreateProjectFolder creates a project folder structure for a NetSuite project
+func (s *Tree) CreateProjectFolder(global *store.GlobalStore, project *store.ProjectStore) error {
+	folder, err := projectPath(global, project)
 	if err != nil {
 		return err
 	}
-	return nil
+	return s.mkdir(folder.Local(s.dirname))
 }
 
-// CreateProjectFolder creates a project folder structure for a NetSuite project
-func (s *Tree) CreateProjectFolder(global *store.GlobalStore, project *store.ProjectStore) error {
-	err := os.MkdirAll(filepath.Join("src", "FileCabinet", "SuiteScripts", global.VendorName, project.Current), os.ModePerm)
+// AddProject adds a project and makes it current: its folder, tsconfig.json
+// and the manifest are written in a single transaction, and the store is only
+// saved once they are in place
+func (s *Tree) AddProject(global *store.GlobalStore, base *store.BaseStore, name string, description string, apiVersion string) (*store.ProjectStore, error) {
+	project, err := base.NewProject(name, description, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	err = s.atomically(func() error {
+		err := s.CreateProjectFolder(global, project)
+		if err != nil {
+			return err
+		}
+		err = s.CreateManifest(project)
+		if err != nil {
+			return err
+		}
+		return s.UpdateTsConfig(global, project)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, base.SaveProject(project)
+}
+
+// RenameProject renames a project end to end: its folder, the @project tag
+// of its scripts, the script files its objects refer to, its manifest and the
+// store, all in a single transaction
+func (s *Tree) RenameProject(global *store.GlobalStore, base *store.BaseStore, name string, newName string) (*store.ProjectStore, error) {
+	project, err := base.RenamedProject(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	fromPath, err := NewCabinetPath("SuiteScripts", global.VendorName, name)
+	if err != nil {
+		return nil, err
+	}
+	toPath, err := NewCabinetPath("SuiteScripts", global.VendorName, newName)
+	if err != nil {
+		return nil, err
+	}
+	from, to := fromPath.Local(s.dirname), toPath.Local(s.dirname)
+	// Refuse to merge into an existing folder
+	if util.Exists(from) && util.Exists(to) {
+		return nil, fmt.Errorf("folder %s already exists", to)
+	}
+	storePath, storeContent, err := base.EncodeProject(project)
+	if err != nil {
+		return nil, err
+	}
+	headerPattern := regexp.MustCompile(`(?m)^(\s*\*\s*@project:\s*)` + regexp.QuoteMeta(name) + `\s*$`)
+
+	err = s.atomically(func() error {
+		// Move every file of the project folder, updating the script headers
+		if util.Exists(from) {
+			err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				content, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(from, path)
+				if err != nil {
+					return err
+				}
+				updated := headerPattern.ReplaceAllString(string(content), "${1}"+strings.ReplaceAll(newName, "$", "$$"))
+				err = s.createFile(filepath.Join(to, rel), updated)
+				if err != nil {
+					return err
+				}
+				return s.removeFile(path)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		// Point the objects at the new folder
+		objects, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+		if err != nil {
+			return err
+		}
+		for _, path := range objects {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			updated := strings.ReplaceAll(string(content), escapeXML(fromPath.String()+"/"), escapeXML(toPath.String()+"/"))
+			if updated != string(content) {
+				err = s.createFile(path, updated)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// Save the store and the manifest with the new name
+		err = s.createFile(storePath, storeContent)
+		if err != nil {
+			return err
+		}
+		return s.CreateManifest(project)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Clear the folders left empty by the move
+	if util.Exists(from) {
+		_ = removeEmptyDirs(from)
+	}
+	return project, nil
+}
+
+// removeEmptyDirs removes a folder when it holds nothing but empty folders
+func removeEmptyDirs(dir string) error {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return err
 	}
-	return nil
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return fmt.Errorf("%s is not empty", dir)
+		}
+		err = removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return os.Remove(dir)
+}
+
+// ProjectObjects returns the objects whose script file is in the folder of a
+// project, relative to the project root
+func (s *Tree) ProjectObjects(global *store.GlobalStore, name string) ([]string, error) {
+	folder, err := NewCabinetPath("SuiteScripts", global.VendorName, name)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, path := range objects {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		reference := "<scriptfile>" + escapeXML("["+folder.String()+"/")
+		if strings.Contains(scriptFilePattern.FindString(string(content)), reference) {
+			matches = append(matches, filepath.Join("src", "Objects", filepath.Base(path)))
+		}
+	}
+	return matches, nil
+}
+
+// RemoveProject removes a project from the store in a single transaction with
+// its folder, the objects of its scripts and the manifest, unless the files
+// are kept
+func (s *Tree) RemoveProject(global *store.GlobalStore, base *store.BaseStore, name string, keepFiles bool) (*store.ProjectStore, error) {
+	project, err := base.RemovedProject(name)
+	if err != nil {
+		return nil, err
+	}
+	folder, err := NewCabinetPath("SuiteScripts", global.VendorName, name)
+	if err != nil {
+		return nil, err
+	}
+	dir := folder.Local(s.dirname)
+	storePath, storeContent, err := base.EncodeProject(project)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.atomically(func() error {
+		if !keepFiles {
+			// Remove every file of the project folder
+			if util.Exists(dir) {
+				err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+					if err != nil || d.IsDir() {
+						return err
+					}
+					return s.removeFile(path)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			// Remove the objects left without a script file
+			objects, err := s.ProjectObjects(global, name)
+			if err != nil {
+				return err
+			}
+			for _, object := range objects {
+				err = s.removeFile(filepath.Join(s.dirname, object))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// Save the store and the manifest of the new current project
+		err := s.createFile(storePath, storeContent)
+		if err != nil {
+			return err
+		}
+		if project.Current == "" {
+			return nil
+		}
+		return s.CreateManifest(project)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Clear the folders left empty by the removal
+	if !keepFiles && util.Exists(dir) {
+		_ = removeEmptyDirs(dir)
+	}
+	return project, nil
 }
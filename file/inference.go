@@ -0,0 +1,312 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"netsuite-companion/inference"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errDeclined is returned when the user declines the model output
+var errDeclined = errors.New("model output declined, nothing was written")
+
+// instructed checks if inference was asked for
+func (o *ScriptOptions) instructed() bool {
+	return o.Instruct != "" || o.InstructFile != ""
+}
+
+// instructions returns the inference instructions, joining the flag text and the file content
+func (o *ScriptOptions) instructions() (string, error) {
+	instruct := o.Instruct
+	if o.InstructFile != "" {
+		content, err := os.ReadFile(o.InstructFile)
+		if err != nil {
+			return "", err
+		}
+		instruct = strings.TrimSpace(strings.Join([]string{instruct, string(content)}, "\n\n"))
+	}
+	if instruct == "" {
+		return "", fmt.Errorf("instructions must be non-empty")
+	}
+	return instruct, nil
+}
+
+// inferScript runs the parsed template through the inference provider, along
+// with the project context when asked for, and, in preview mode, shows both versions and asks before keeping the model output
+func (s *Tree) inferScript(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions, parsedTS string) (string, error) {
+	// Get the instructions
+	instruct, err := opts.instructions()
+	if err != nil {
+		return "", err
+	}
+	// Add the project context when asked for
+	context, err := s.projectContext(global, project, opts)
+	if err != nil {
+		return "", err
+	}
+	if context != "" {
+		instruct = instruct + "\n\n" + context
+	}
+	// Run the inference, checking the output
+	inferred, err := s.runCheckedInference(global, opts, instruct, parsedTS)
+	if err != nil {
+		return "", err
+	}
+	if !opts.Preview {
+		return inferred, nil
+	}
+	// Show the template and model output side by side
+	fmt.Println(util.SideBySide("TEMPLATE", parsedTS, "MODEL", inferred))
+	ok, err := util.Confirm("Write the model output?", opts.Yes)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errDeclined
+	}
+	return inferred, nil
+}
+
+// runCheckedInference runs the inference and checks the code it returns
+// still has the JSDoc tags and entry points of the template. Rejected output
+// is retried with a corrective prompt, and the template is kept when every
+// attempt fails.
+func (s *Tree) runCheckedInference(global *store.GlobalStore, opts *ScriptOptions, instruct string, parsedTS string) (string, error) {
+	system := instruct
+	for attempt := 0; attempt <= global.InferenceRetries; attempt++ {
+		// Run the inference and extract the code
+		answer, err := s.runInference(global, opts, system, parsedTS)
+		if err != nil {
+			return "", err
+		}
+		code := inference.ExtractCode(answer)
+		// Keep the code when nothing was lost
+		problems := checkOutput(parsedTS, code)
+		if len(problems) == 0 {
+			return code, nil
+		}
+		fmt.Printf("Model output rejected:\n  - %s\n", strings.Join(problems, "\n  - "))
+		// Ask again with the problems spelled out
+		system = fmt.Sprintf("%s\n\nA previous answer was rejected because of the following problems:\n- %s\n"+
+			"Return only the complete TypeScript file without markdown fences or explanations, "+
+			"keeping every JSDoc tag of the header with its value and every exported entry point.",
+			instruct, strings.Join(problems, "\n- "))
+	}
+	fmt.Println("Falling back to the template output")
+	return parsedTS, nil
+}
+
+// runInference sends the parsed template and the instructions to the
+// configured inference provider, going through the response cache
+func (s *Tree) runInference(global *store.GlobalStore, opts *ScriptOptions, instruct string, parsedTS string) (string, error) {
+	config := inferenceConfig(global, opts)
+	mode, err := cacheMode(global.InferenceCache, opts)
+	if err != nil {
+		return "", err
+	}
+	// Use the cached response when there is one
+	key := cacheKey(config.Provider, config.Model, instruct, parsedTS)
+	if mode != cacheOff {
+		cached, ok, err := s.readCache(key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			fmt.Printf("Using cached inference response %s\n", key[:12])
+			return cached, nil
+		}
+	}
+	if mode == cacheReplay {
+		return "", fmt.Errorf("no cached inference response %s in replay mode", key[:12])
+	}
+	// Call the provider
+	provider, err := s.newProvider(config)
+	if err != nil {
+		return "", err
+	}
+	answer, err := provider.Complete(context.Background(), instruct, parsedTS)
+	if err != nil {
+		return "", err
+	}
+	// Store the response
+	if mode == cacheOn {
+		err = s.writeCache(key, config.Provider, config.Model, answer)
+		if err != nil {
+			return "", err
+		}
+	}
+	return answer, nil
+}
+
+// inferenceConfig returns the provider configuration set in the global store, applying the option overrides
+func inferenceConfig(global *store.GlobalStore, opts *ScriptOptions) inference.Config {
+	config := inference.Config{
+		Provider:    global.InferenceProvider,
+		Model:       global.InferenceModel,
+		BaseURL:     global.InferenceBaseURL,
+		ApiVersion:  global.InferenceApiVersion,
+		Temperature: global.InferenceTemperature,
+		Timeout:     time.Duration(global.InferenceTimeout) * time.Second,
+	}
+	// Apply the provider and model overrides
+	if opts.Provider != "" {
+		config.Provider = opts.Provider
+	}
+	if opts.Model != "" {
+		config.Model = opts.Model
+	}
+	return config
+}
+
+// newProvider creates the inference provider for a configuration, adding the API key
+func (s *Tree) newProvider(config inference.Config) (inference.Provider, error) {
+	// Get the API key from the secret backend
+	if s.secrets == nil {
+		return nil, fmt.Errorf("no secret backend set")
+	}
+	apiKey, err := s.secrets.Get("openai_api_key")
+	if err != nil {
+		return nil, err
+	}
+	config.ApiKey = apiKey
+	return inference.New(config)
+}
+
+// tagPattern matches a SuiteScript JSDoc tag and its value
+var tagPattern = regexp.MustCompile(`(?m)^\s*\*\s*@(N\w+)[ \t]+(.+?)\s*$`)
+
+// exportPatterns match the names of exported entry points
+var exportPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*export\s+(?:let|const|var|function|async\s+function)\s+(\w+)`),
+	regexp.MustCompile(`\[\s*["'](\w+)["']\s*\]\s*:`),
+}
+
+// exportObjectPattern matches the start of an export assignment of an object literal
+var exportObjectPattern = regexp.MustCompile(`export\s*=\s*\{`)
+
+// objectKeyPattern matches the key of an object literal entry, a property or a method
+var objectKeyPattern = regexp.MustCompile(`^(?:async\s+)?\[?\s*["']?(\w+)`)
+
+// checkOutput lists what the model output lost from the template output:
+// SuiteScript JSDoc tags and their values, and exported entry points
+func checkOutput(template string, output string) []string {
+	var problems []string
+
+	// Check every tag of the template keeps its value
+	tags := map[string]string{}
+	for _, match := range tagPattern.FindAllStringSubmatch(output, -1) {
+		tags[match[1]] = match[2]
+	}
+	for _, match := range tagPattern.FindAllStringSubmatch(template, -1) {
+		value, ok := tags[match[1]]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing JSDoc tag @%s %s", match[1], match[2]))
+		} else if value != match[2] {
+			problems = append(problems, fmt.Sprintf("JSDoc tag @%s must be %q, not %q", match[1], match[2], value))
+		}
+	}
+
+	// Check every entry point of the template is still exported
+	exports := exportedNames(output)
+	for _, name := range sortedKeys(exportedNames(template)) {
+		if !exports[name] {
+			problems = append(problems, fmt.Sprintf("missing exported entry point %s", name))
+		}
+	}
+	return problems
+}
+
+// exportedNames returns the names exported by a TypeScript file
+func exportedNames(code string) map[string]bool {
+	names := map[string]bool{}
+	for _, pattern := range exportPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(code, -1) {
+			names[match[1]] = true
+		}
+	}
+	// Object exports such as export = {get, post: handler}
+	for _, match := range exportObjectPattern.FindAllStringIndex(code, -1) {
+		for _, entry := range objectEntries(code[match[1]:]) {
+			if key := objectKeyPattern.FindStringSubmatch(strings.TrimSpace(entry)); key != nil {
+				names[key[1]] = true
+			}
+		}
+	}
+	return names
+}
+
+// objectEntries splits the body of an object literal, starting after its
+// opening brace, into its top level entries. Nested braces, brackets and
+// parentheses are balanced, and strings and comments are skipped over.
+func objectEntries(body string) []string {
+	var entries []string
+	var entry strings.Builder
+	depth := 0
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case strings.HasPrefix(body[i:], "//"):
+			// Skip a line comment
+			end := strings.IndexByte(body[i:], '\n')
+			if end < 0 {
+				return entries
+			}
+			i += end
+			continue
+		case strings.HasPrefix(body[i:], "/*"):
+			// Skip a block comment
+			end := strings.Index(body[i+2:], "*/")
+			if end < 0 {
+				return entries
+			}
+			i += end + 3
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			// Keep a string whole, commas and braces included
+			end := i + 1
+			for end < len(body) && body[end] != c {
+				if body[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(body) {
+				return entries
+			}
+			entry.WriteString(body[i : end+1])
+			i = end
+			continue
+		case c == '{' || c == '[' || c == '(':
+			depth++
+		case c == '}' || c == ']' || c == ')':
+			if depth == 0 {
+				// The closing brace of the object
+				return append(entries, entry.String())
+			}
+			depth--
+		case c == ',' && depth == 0:
+			entries = append(entries, entry.String())
+			entry.Reset()
+			continue
+		}
+		entry.WriteByte(c)
+	}
+	return entries
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
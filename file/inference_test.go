@@ -0,0 +1,114 @@
+package file
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// restletHeader is the JSDoc header of a generated RESTlet
+const restletHeader = `/**
+ * @NScriptName Order Sync
+ * @NApiVersion 2.1
+ * @NScriptType Restlet
+ */
+`
+
+func TestExportedNames(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "declarations",
+			code: "export const get = () => 1;\nexport function post() {}\nexport async function put() {}\n",
+			want: []string{"get", "post", "put"},
+		},
+		{
+			name: "shorthand object",
+			code: "export = {get, post};",
+			want: []string{"get", "post"},
+		},
+		{
+			name: "quoted keys",
+			code: `export = {["get"]: get, "delete": remove, 'put': put};`,
+			want: []string{"delete", "get", "put"},
+		},
+		{
+			name: "nested expressions",
+			code: `export = {
+    get: (params: {id: string, type: string}) => lookup(params, {columns: ["a", "b"]}),
+    post: wrap(handler, {retries: 2}),
+    put,
+};`,
+			want: []string{"get", "post", "put"},
+		},
+		{
+			name: "methods, comments and strings",
+			code: `export = {
+    /** GET handler, {not} an entry */
+    get(params: object) { return "}, fake: 1"; },
+    // post: disabled,
+    async put(body: object) { return ` + "`a, ${body}`" + `; },
+    ...shared,
+};`,
+			want: []string{"get", "put"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := sortedKeys(exportedNames(test.code))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	template := restletHeader + "const get = () => 1;\nconst post = () => 2;\nexport = {get, post};\n"
+	tests := []struct {
+		name   string
+		output string
+		// Substrings of the expected problems, in order
+		want []string
+	}{
+		{
+			name:   "unchanged",
+			output: template,
+		},
+		{
+			name:   "rewritten entry points",
+			output: restletHeader + "export = {\n    get: (p: {id: string}) => find(p, {a: 1}),\n    post: save,\n};\n",
+		},
+		{
+			name:   "missing tag",
+			output: strings.Replace(template, " * @NScriptType Restlet\n", "", 1),
+			want:   []string{"missing JSDoc tag @NScriptType Restlet"},
+		},
+		{
+			name:   "changed tag",
+			output: strings.Replace(template, "@NApiVersion 2.1", "@NApiVersion 2.x", 1),
+			want:   []string{`JSDoc tag @NApiVersion must be "2.1", not "2.x"`},
+		},
+		{
+			name:   "missing entry point",
+			output: restletHeader + "export = {get: (p: {id: string}) => p, other};\n",
+			want:   []string{"missing exported entry point post"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := checkOutput(template, test.output)
+			if len(problems) != len(test.want) {
+				t.Fatalf("got problems %q, want %q", problems, test.want)
+			}
+			for i, want := range test.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("got problem %q, want %q", problems[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package file
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"netsuite-companion/store"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// headerTagPattern matches a JSDoc tag line such as " * @NScriptId customscript_x"
+var headerTagPattern = regexp.MustCompile(`^\s*\*\s*@(\w+):?\s*(.*?)\s*$`)
+
+// InventoryEntry describes a script or object found in the project
+type InventoryEntry struct {
+	// Script name, from @NScriptName or the object name
+	Name string `json:"name"`
+	// Script type from @NScriptType, or the object type
+	Type string `json:"type"`
+	// Script id
+	ScriptId string `json:"script_id"`
+	// Deployment ids declared in the object
+	DeploymentIds []string `json:"deployment_ids"`
+	// Description from the script header
+	Description string `json:"description,omitempty"`
+	// Author from the script header
+	Author string `json:"author,omitempty"`
+	// Script file, relative to the project root
+	Path string `json:"path,omitempty"`
+	// Object file, relative to the project root
+	Object string `json:"object,omitempty"`
+	// Whether the object file exists
+	HasObject bool `json:"has_object"`
+}
+
+// headerTag is a JSDoc tag of a script header
+type headerTag struct {
+	// Tag value
+	value string
+	// Line of the tag in the file
+	line int
+}
+
+// objectId is a script id declared in an object file
+type objectId struct {
+	// Script id
+	id string
+	// Line of the declaration in the file
+	line int
+}
+
+// sdfObject is an object file of src/Objects
+type sdfObject struct {
+	// Path of the object file
+	path string
+	// Object type, the name of the root element
+	kind string
+	// Object name
+	name string
+	// Script id of the object
+	scriptId objectId
+	// Script file reference, as written
+	scriptFile string
+	// Line of the script file reference
+	scriptFileLine int
+	// Deployments of the object
+	deployments []objectId
+	// Every script id declared in the object, the object itself included
+	ids []objectId
+}
+
+// readHeader returns the JSDoc tags of the first comment block of a script file
+func readHeader(path string) (map[string]headerTag, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tags := map[string]headerTag{}
+	scanner := bufio.NewScanner(file)
+	inside := false
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !inside {
+			inside = strings.HasPrefix(strings.TrimSpace(text), "/**")
+			continue
+		}
+		if strings.Contains(text, "*/") {
+			break
+		}
+		match := headerTagPattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		if _, ok := tags[match[1]]; !ok {
+			tags[match[1]] = headerTag{value: match[2], line: line}
+		}
+	}
+	return tags, scanner.Err()
+}
+
+// readObject parses an object file, keeping the lines of its ids
+func readObject(path string) (*sdfObject, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	object := &sdfObject{path: path}
+	decoder := xml.NewDecoder(file)
+	depth := 0
+	// Element whose text is being read
+	reading := ""
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return object, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := decoder.InputPos()
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				object.kind = t.Name.Local
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Local != "scriptid" {
+					continue
+				}
+				id := objectId{id: attr.Value, line: line}
+				object.ids = append(object.ids, id)
+				if depth == 1 {
+					object.scriptId = id
+				}
+				if t.Name.Local == "scriptdeployment" {
+					object.deployments = append(object.deployments, id)
+				}
+			}
+			reading = ""
+			if depth == 2 && (t.Name.Local == "scriptfile" || t.Name.Local == "name" || t.Name.Local == "recordname" || t.Name.Local == "label") {
+				reading = t.Name.Local
+			}
+		case xml.CharData:
+			value := strings.TrimSpace(string(t))
+			switch reading {
+			case "scriptfile":
+				object.scriptFile = value
+				object.scriptFileLine = line
+			case "name", "recordname", "label":
+				if object.name == "" {
+					object.name = value
+				}
+			}
+		case xml.EndElement:
+			depth--
+			reading = ""
+		}
+	}
+}
+
+// readObjects parses every object file of src/Objects
+func (s *Tree) readObjects() ([]*sdfObject, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	var objects []*sdfObject
+	for _, path := range paths {
+		object, err := readObject(path)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// scriptFiles returns the TypeScript files of a FileCabinet folder and its subfolders
+func scriptFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".ts") && !strings.HasSuffix(path, ".d.ts") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// Inventory lists the scripts of the current project with their objects,
+// followed by the objects that belong to no script of the project
+func (s *Tree) Inventory(global *store.GlobalStore, project *store.ProjectStore) ([]InventoryEntry, error) {
+	objects, err := s.readObjects()
+	if err != nil {
+		return nil, err
+	}
+	folder, err := projectPath(global, project)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := scriptFiles(folder.Local(s.dirname))
+	if err != nil {
+		return nil, err
+	}
+
+	// List the scripts by their header
+	used := map[*sdfObject]bool{}
+	var entries []InventoryEntry
+	for _, path := range paths {
+		tags, err := readHeader(path)
+		if err != nil {
+			return nil, err
+		}
+		scriptType, ok := tags["NScriptType"]
+		if !ok {
+			continue
+		}
+		entry := InventoryEntry{
+			Name:          tags["NScriptName"].value,
+			Type:          scriptType.value,
+			ScriptId:      tags["NScriptId"].value,
+			DeploymentIds: []string{},
+			Description:   tags["description"].value,
+			Author:        tags["author"].value,
+			Path:          relPath(s.dirname, path),
+		}
+		// Find the object named after the script, or declaring its id
+		objectPath := s.scriptObjectPath(path)
+		for _, object := range objects {
+			if object.path == objectPath || (entry.ScriptId != "" && object.scriptId.id == entry.ScriptId) {
+				used[object] = true
+				entry.Object, entry.HasObject = relPath(s.dirname, object.path), true
+				for _, d := range object.deployments {
+					entry.DeploymentIds = append(entry.DeploymentIds, d.id)
+				}
+				break
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	// List the other objects, leaving out the scripts of other projects
+	for _, object := range objects {
+		if used[object] {
+			continue
+		}
+		if object.scriptFile != "" && !ParseCabinetPath(object.scriptFile).HasPrefix(folder) {
+			continue
+		}
+		entry := InventoryEntry{
+			Name:          object.name,
+			Type:          object.kind,
+			ScriptId:      object.scriptId.id,
+			DeploymentIds: []string{},
+			Object:        relPath(s.dirname, object.path),
+			HasObject:     true,
+		}
+		for _, d := range object.deployments {
+			entry.DeploymentIds = append(entry.DeploymentIds, d.id)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,299 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Patterns of the script name in a script header and an object
+var (
+	headerNamePattern = regexp.MustCompile(`(?m)^(\s*\*\s*@NScriptName\s+).*$`)
+	objectNamePattern = regexp.MustCompile(`(?m)^(  <name>).*(</name>)$`)
+	scriptFilePattern = regexp.MustCompile(`<scriptfile>[^<]*</scriptfile>`)
+)
+
+// moveEdit is a file change planned by a move
+type moveEdit struct {
+	// Current path of the file
+	from string
+	// New path of the file, the same as from when only the content changes
+	to string
+	// Current content
+	before string
+	// New content
+	after string
+}
+
+// moveNames holds the names, ids and paths a move renames from one value to another
+type moveNames struct {
+	// Script name
+	name string
+	// File name without extension, as in acm_order_sync_userevent
+	base string
+	// Script id
+	scriptId string
+	// Prefix of the deployment ids
+	deploymentId string
+	// FileCabinet path of the compiled script
+	reference CabinetPath
+}
+
+// MoveScript renames a script end to end: its TypeScript file, its object,
+// its script and deployment ids, its header and every import of it. With
+// dryRun the planned edits are printed and nothing is written.
+func (s *Tree) MoveScript(global *store.GlobalStore, project *store.ProjectStore, script string, name string, dryRun bool) error {
+	edits, err := s.planMove(global, project, script, name)
+	if err != nil {
+		return err
+	}
+
+	// Show the plan
+	if dryRun {
+		for _, edit := range edits {
+			if edit.from != edit.to {
+				fmt.Printf("rename %s -> %s\n", relPath(s.dirname, edit.from), relPath(s.dirname, edit.to))
+			}
+			if edit.before != edit.after {
+				fmt.Print(util.UnifiedDiff(relPath(s.dirname, edit.to), edit.before, edit.after))
+			}
+		}
+		return nil
+	}
+
+	// Apply every edit in a single transaction
+	err = s.atomically(func() error {
+		for _, edit := range edits {
+			err := s.createFile(edit.to, edit.after)
+			if err != nil {
+				return err
+			}
+			if edit.from != edit.to {
+				err = s.removeFile(edit.from)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, edit := range edits {
+		if edit.from != edit.to {
+			fmt.Printf("Renamed %s -> %s\n", relPath(s.dirname, edit.from), relPath(s.dirname, edit.to))
+		} else {
+			fmt.Printf("Updated %s\n", relPath(s.dirname, edit.to))
+		}
+	}
+	return nil
+}
+
+// planMove returns the edits renaming a script, checking the new files and ids are free
+func (s *Tree) planMove(global *store.GlobalStore, project *store.ProjectStore, script string, name string) ([]moveEdit, error) {
+	// Find the script file and its type
+	if filepath.Ext(script) == "" {
+		script += ".ts"
+	}
+	tsPath, err := s.resolveScript(global, project, script)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSuffix(filepath.Base(tsPath), filepath.Ext(tsPath))
+	i := strings.LastIndex(base, "_")
+	if i < 0 || !slices.Contains(ScriptTypes(), base[i+1:]) {
+		return nil, fmt.Errorf("cannot tell the script type of %s, expected a <prefix>_<name>_<type>.ts file", filepath.Base(tsPath))
+	}
+	scriptType, oldPattern := base[i+1:], base[:i]
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("new name must be non-empty")
+	}
+
+	// Get the old and new names
+	tags, err := readHeader(tsPath)
+	if err != nil {
+		return nil, err
+	}
+	folderPath := relPath(filepath.Join(s.dirname, "src", "FileCabinet"), filepath.Dir(tsPath))
+	if strings.HasPrefix(folderPath, "..") {
+		return nil, fmt.Errorf("%s is not in src/FileCabinet", tsPath)
+	}
+	folder := ParseCabinetPath(folderPath)
+	newPattern := vendorPattern(global, name)
+	fromReference, err := folder.Join(base + ".js")
+	if err != nil {
+		return nil, err
+	}
+	toReference, err := folder.Join(newPattern + "_" + scriptType + ".js")
+	if err != nil {
+		return nil, err
+	}
+	from := moveNames{
+		name:         tags["NScriptName"].value,
+		base:         base,
+		scriptId:     "customscript_" + oldPattern,
+		deploymentId: "customdeploy_" + oldPattern,
+		reference:    fromReference,
+	}
+	to := moveNames{
+		name:         name,
+		base:         newPattern + "_" + scriptType,
+		scriptId:     "customscript_" + newPattern,
+		deploymentId: "customdeploy_" + newPattern,
+		reference:    toReference,
+	}
+	if from.base == to.base {
+		return nil, fmt.Errorf("%s is already named %s", filepath.Base(tsPath), to.base)
+	}
+	newTsPath := filepath.Join(filepath.Dir(tsPath), to.base+".ts")
+	jsPath, newJsPath := strings.TrimSuffix(tsPath, filepath.Ext(tsPath))+".js", filepath.Join(filepath.Dir(tsPath), to.base+".js")
+	objectPath, newObjectPath := s.scriptObjectPath(tsPath), s.scriptObjectPath(newTsPath)
+
+	// Read the object, keeping its ids when they were overridden
+	var object *sdfObject
+	if util.Exists(objectPath) {
+		object, err = readObject(objectPath)
+		if err != nil {
+			return nil, err
+		}
+		from.scriptId, from.reference = object.scriptId.id, ParseCabinetPath(object.scriptFile)
+		// Keep script ids that do not follow the file name
+		if from.scriptId != "customscript_"+oldPattern {
+			to.scriptId = from.scriptId
+		}
+	}
+	renames := map[string]string{from.scriptId: to.scriptId}
+	if object != nil {
+		for _, d := range object.deployments {
+			// Keep deployment ids that do not follow the file name
+			rest, ok := strings.CutPrefix(d.id, from.deploymentId)
+			if ok && (rest == "" || strings.HasPrefix(rest, "_")) {
+				renames[d.id] = to.deploymentId + rest
+			}
+		}
+	}
+
+	// Refuse to replace existing files or reuse the ids of another object
+	paths := []string{newTsPath}
+	if object != nil {
+		paths = append(paths, newObjectPath)
+	}
+	if util.Exists(jsPath) {
+		paths = append(paths, newJsPath)
+	}
+	for _, path := range paths {
+		if util.Exists(path) {
+			return nil, fmt.Errorf("%s already exists", relPath(s.dirname, path))
+		}
+	}
+	used, err := s.objectIds(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	for old, id := range renames {
+		if path, ok := used[id]; ok && old != id {
+			return nil, fmt.Errorf("%s is already used by %s", id, relPath(s.dirname, path))
+		}
+	}
+
+	// Rename the script file and update its header
+	var edits []moveEdit
+	content, err := os.ReadFile(tsPath)
+	if err != nil {
+		return nil, err
+	}
+	updated := renameIds(string(content), renames)
+	updated = headerNamePattern.ReplaceAllString(updated, "${1}"+strings.ReplaceAll(to.name, "$", "$$"))
+	edits = append(edits, moveEdit{from: tsPath, to: newTsPath, before: string(content), after: updated})
+
+	// Rename the compiled file next to the script, so the object never refers to a missing file
+	if util.Exists(jsPath) {
+		content, err := os.ReadFile(jsPath)
+		if err != nil {
+			return nil, err
+		}
+		updated := renameIds(string(content), renames)
+		updated = headerNamePattern.ReplaceAllString(updated, "${1}"+strings.ReplaceAll(to.name, "$", "$$"))
+		edits = append(edits, moveEdit{from: jsPath, to: newJsPath, before: string(content), after: updated})
+	}
+
+	// Rename the object and update its name, ids, deployment titles and script file
+	if object != nil {
+		content, err := os.ReadFile(objectPath)
+		if err != nil {
+			return nil, err
+		}
+		updated := renameIds(string(content), renames)
+		updated = objectNamePattern.ReplaceAllString(updated, "${1}"+strings.ReplaceAll(escapeXML(to.name), "$", "$$")+"${2}")
+		if from.name != "" {
+			updated = strings.ReplaceAll(updated, "<title>"+escapeXML(from.name), "<title>"+escapeXML(to.name))
+		}
+		updated = scriptFilePattern.ReplaceAllLiteralString(updated, "<scriptfile>"+escapeXML(to.reference.Reference())+"</scriptfile>")
+		edits = append(edits, moveEdit{from: objectPath, to: newObjectPath, before: string(content), after: updated})
+	}
+
+	// Update the other objects referring to the script
+	others, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range others {
+		if path == objectPath {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		updated := renameIds(string(content), renames)
+		updated = strings.ReplaceAll(updated, escapeXML(from.reference.String()), escapeXML(to.reference.String()))
+		if updated != string(content) {
+			edits = append(edits, moveEdit{from: path, to: path, before: string(content), after: updated})
+		}
+	}
+
+	// Update the imports and ids in the other TypeScript files of the project
+	projectFolder, err := projectPath(global, project)
+	if err != nil {
+		return nil, err
+	}
+	scripts, err := scriptFiles(projectFolder.Local(s.dirname))
+	if err != nil {
+		return nil, err
+	}
+	importPattern := regexp.MustCompile(`((?:from|import|require\()\s*["'][^"'\n]*?)\b` + regexp.QuoteMeta(from.base) + `((?:\.js)?["'])`)
+	for _, path := range scripts {
+		if path == tsPath {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		updated := importPattern.ReplaceAllString(string(content), "${1}"+to.base+"${2}")
+		updated = renameIds(updated, renames)
+		if updated != string(content) {
+			edits = append(edits, moveEdit{from: path, to: path, before: string(content), after: updated})
+		}
+	}
+	return edits, nil
+}
+
+// renameIds replaces whole ids in a file content
+func renameIds(content string, renames map[string]string) string {
+	for old, id := range renames {
+		if old == id {
+			continue
+		}
+		pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(old) + `\b`)
+		content = pattern.ReplaceAllLiteralString(content, id)
+	}
+	return content
+}
@@ -0,0 +1,197 @@
+package file
+
+import (
+	"netsuite-companion/store"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Files of a project with a user event script, its compiled file, its object
+// and a module importing it
+const (
+	moveFolder = "src/FileCabinet/SuiteScripts/Acme/Orders/"
+	moveScript = `/**
+ * @NScriptName Order Sync
+ * @NScriptId customscript_acm_order_sync
+ * @NScriptType UserEventScript
+ */
+export const deployment = "customdeploy_acm_order_sync";
+`
+	moveObject = `<usereventscript scriptid="customscript_acm_order_sync">
+  <name>Order Sync</name>
+  <scriptfile>[/SuiteScripts/Acme/Orders/acm_order_sync_userevent.js]</scriptfile>
+  <scriptdeployments>
+    <scriptdeployment scriptid="customdeploy_acm_order_sync">
+      <title>Order Sync</title>
+    </scriptdeployment>
+    <scriptdeployment scriptid="customdeploy_manual">
+      <title>Manual</title>
+    </scriptdeployment>
+  </scriptdeployments>
+</usereventscript>
+`
+	moveModule = `import * as sync from "./acm_order_sync_userevent";
+import {other} from "./acm_order_sync_userevent_helpers";
+
+export const id = "customscript_acm_order_sync";
+`
+)
+
+// writeTree writes files relative to a root folder
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPlanMove(t *testing.T) {
+	tests := []struct {
+		name string
+		// Files of the project, replacing the default ones
+		files map[string]string
+		// Default files the project does not have
+		missing []string
+		// Renamed files
+		renames map[string]string
+		// Substrings expected in the new content of each file
+		want map[string][]string
+		// Substrings no file may keep
+		gone []string
+		// Expected error
+		err string
+	}{
+		{
+			name: "renames files, ids and imports",
+			renames: map[string]string{
+				moveFolder + "acm_order_sync_userevent.ts": moveFolder + "acm_order_import_userevent.ts",
+				moveFolder + "acm_order_sync_userevent.js": moveFolder + "acm_order_import_userevent.js",
+				"src/Objects/acm_order_sync_userevent.xml": "src/Objects/acm_order_import_userevent.xml",
+			},
+			want: map[string][]string{
+				moveFolder + "acm_order_import_userevent.ts": {"@NScriptName Order Import", "@NScriptId customscript_acm_order_import", `"customdeploy_acm_order_import"`},
+				moveFolder + "acm_order_import_userevent.js": {"@NScriptName Order Import", "@NScriptId customscript_acm_order_import"},
+				"src/Objects/acm_order_import_userevent.xml": {
+					`<usereventscript scriptid="customscript_acm_order_import">`,
+					"<name>Order Import</name>",
+					"<scriptfile>[/SuiteScripts/Acme/Orders/acm_order_import_userevent.js]</scriptfile>",
+					`<scriptdeployment scriptid="customdeploy_acm_order_import">`,
+					"<title>Order Import</title>",
+					`<scriptdeployment scriptid="customdeploy_manual">`,
+				},
+				moveFolder + "acm_helpers_module.ts": {`from "./acm_order_import_userevent";`, `from "./acm_order_sync_userevent_helpers";`, `"customscript_acm_order_import"`},
+			},
+			gone: []string{"customscript_acm_order_sync", "customdeploy_acm_order_sync\""},
+		},
+		{
+			name: "keeps overridden ids",
+			files: map[string]string{
+				"src/Objects/acm_order_sync_userevent.xml": strings.NewReplacer(
+					"customscript_acm_order_sync", "customscript_acm_legacy",
+					"customdeploy_acm_order_sync", "customdeploy_acm_legacy",
+				).Replace(moveObject),
+			},
+			renames: map[string]string{
+				moveFolder + "acm_order_sync_userevent.ts": moveFolder + "acm_order_import_userevent.ts",
+				moveFolder + "acm_order_sync_userevent.js": moveFolder + "acm_order_import_userevent.js",
+				"src/Objects/acm_order_sync_userevent.xml": "src/Objects/acm_order_import_userevent.xml",
+			},
+			want: map[string][]string{
+				"src/Objects/acm_order_import_userevent.xml": {
+					`<usereventscript scriptid="customscript_acm_legacy">`,
+					`<scriptdeployment scriptid="customdeploy_acm_legacy">`,
+					"<scriptfile>[/SuiteScripts/Acme/Orders/acm_order_import_userevent.js]</scriptfile>",
+				},
+				moveFolder + "acm_helpers_module.ts": {`from "./acm_order_import_userevent";`},
+			},
+		},
+		{
+			name:    "renames a script without a compiled file",
+			missing: []string{moveFolder + "acm_order_sync_userevent.js"},
+			renames: map[string]string{
+				moveFolder + "acm_order_sync_userevent.ts": moveFolder + "acm_order_import_userevent.ts",
+				"src/Objects/acm_order_sync_userevent.xml": "src/Objects/acm_order_import_userevent.xml",
+			},
+		},
+		{
+			name: "refuses to replace a compiled file",
+			files: map[string]string{
+				moveFolder + "acm_order_import_userevent.js": moveScript,
+			},
+			err: "acm_order_import_userevent.js already exists",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			files := map[string]string{
+				moveFolder + "acm_order_sync_userevent.ts": moveScript,
+				moveFolder + "acm_order_sync_userevent.js": moveScript,
+				moveFolder + "acm_helpers_module.ts":       moveModule,
+				"src/Objects/acm_order_sync_userevent.xml": moveObject,
+			}
+			for path, content := range test.files {
+				files[path] = content
+			}
+			for _, path := range test.missing {
+				delete(files, path)
+			}
+			writeTree(t, root, files)
+
+			tree := CreateTree(root)
+			global := &store.GlobalStore{VendorName: "Acme", VendorPrefix: "acm"}
+			project := &store.ProjectStore{Current: "Orders"}
+			edits, err := tree.planMove(global, project, "acm_order_sync_userevent", "Order Import")
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Check the renames and the new content of every file
+			renames := map[string]string{}
+			contents := map[string]string{}
+			for _, edit := range edits {
+				from, to := filepath.ToSlash(relPath(root, edit.from)), filepath.ToSlash(relPath(root, edit.to))
+				if from != to {
+					renames[from] = to
+				}
+				contents[to] = edit.after
+			}
+			for from, to := range test.renames {
+				if renames[from] != to {
+					t.Errorf("%s renamed to %q, want %s", from, renames[from], to)
+				}
+			}
+			if len(renames) != len(test.renames) {
+				t.Errorf("got renames %v, want %v", renames, test.renames)
+			}
+			for path, wants := range test.want {
+				for _, want := range wants {
+					if !strings.Contains(contents[path], want) {
+						t.Errorf("%s does not contain %q:\n%s", path, want, contents[path])
+					}
+				}
+			}
+			for path, content := range contents {
+				for _, gone := range test.gone {
+					if strings.Contains(content, gone) {
+						t.Errorf("%s still contains %q:\n%s", path, gone, content)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package file
+
+import (
+	"encoding/xml"
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers around the generated parameter accessor in a script file
+const (
+	paramsBegin = "// nsc:params:begin"
+	paramsEnd   = "// nsc:params:end"
+)
+
+// ScriptParam represents a script parameter
+type ScriptParam struct {
+	// Parameter script ID
+	ScriptId string `xml:"scriptid,attr"`
+	// Parameter label
+	Label string `xml:"label"`
+	// SDF field type
+	Type string `xml:"fieldtype"`
+	// Default value
+	Default string `xml:"defaultvalue"`
+	// Source list or record for select parameters
+	Source string `xml:"selectrecordtype"`
+}
+
+// ParamOptions holds values for a script parameter that would otherwise be prompted for
+type ParamOptions struct {
+	// Parameter id, with or without the custscript_ and vendor prefixes
+	Id string
+	// Parameter label
+	Label string
+	// Field type
+	Type string
+	// Default value
+	Default string
+	// Source list or record for select parameters
+	Source string
+	// Use defaults instead of prompting
+	Yes bool
+}
+
+// Key returns the TypeScript property name of the parameter
+func (p *ScriptParam) Key() string {
+	id := strings.TrimPrefix(p.ScriptId, "custscript_")
+	if _, rest, ok := strings.Cut(id, "_"); ok {
+		id = rest
+	}
+	return camelCase(id)
+}
+
+// accessor returns the TypeScript expression reading the parameter as its value type
+func (p *ScriptParam) accessor() string {
+	get := fmt.Sprintf("script.getParameter({name: \"%s\"})", p.ScriptId)
+	switch fieldTypes[p.Type] {
+	case "boolean":
+		return fmt.Sprintf("/^(T|true)$/.test(String(%s))", get)
+	case "number":
+		return fmt.Sprintf("Number(%s)", get)
+	case "Date":
+		return fmt.Sprintf("%s as Date", get)
+	case "string[]":
+		return fmt.Sprintf("String(%s || \"\").split(\",\").filter(id => id !== \"\")", get)
+	}
+	return fmt.Sprintf("String(%s || \"\")", get)
+}
+
+// parseParams parses parameter definitions given as id:label:type[:default[:source]].
+// The default keeps any further colons, as in URLs and times, except for
+// select parameters whose source follows it.
+func parseParams(global *store.GlobalStore, definitions []string) ([]*ScriptParam, error) {
+	var params []*ScriptParam
+	for _, definition := range definitions {
+		parts := splitDefinition(definition, 4)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid parameter %q, expected id:label:type[:default[:source]]", definition)
+		}
+		if len(parts) > 3 && strings.HasSuffix(strings.ToUpper(parts[2]), "SELECT") {
+			parts = splitDefinition(definition, 5)
+		}
+		opts := &ParamOptions{Id: parts[0], Label: parts[1], Type: parts[2]}
+		if len(parts) > 3 {
+			opts.Default = parts[3]
+		}
+		if len(parts) > 4 {
+			opts.Source = parts[4]
+		}
+		param, err := newParam(global, opts)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// splitDefinition splits a colon separated definition into at most n parts,
+// the last one keeping any further colons. An escaped \: does not split.
+func splitDefinition(definition string, n int) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(definition); i++ {
+		switch {
+		case definition[i] == '\\' && i+1 < len(definition) && definition[i+1] == ':':
+			part.WriteByte(':')
+			i++
+		case definition[i] == ':' && len(parts) < n-1:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(definition[i])
+		}
+	}
+	return append(parts, part.String())
+}
+
+// newParam creates a script parameter, prefixing its id with custscript_ and the vendor prefix
+func newParam(global *store.GlobalStore, opts *ParamOptions) (*ScriptParam, error) {
+	scriptId, err := fieldScriptId(global, "custscript_", opts.Id)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Label == "" {
+		return nil, fmt.Errorf("label of parameter %s must be non-empty", scriptId)
+	}
+	fieldType, source, err := checkFieldType(scriptId, opts.Type, opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	// Checkbox defaults are T or F
+	value := opts.Default
+	if fieldType == "CHECKBOX" && value != "" {
+		value = "F"
+		if isTrue(opts.Default) {
+			value = "T"
+		}
+	}
+	return &ScriptParam{ScriptId: scriptId, Label: opts.Label, Type: fieldType, Default: value, Source: source}, nil
+}
+
+// readParams returns the parameters declared in a script object
+func readParams(content string) ([]*ScriptParam, error) {
+	var object struct {
+		Params []*ScriptParam `xml:"scriptcustomfields>scriptcustomfield"`
+	}
+	err := xml.Unmarshal([]byte(content), &object)
+	if err != nil {
+		return nil, err
+	}
+	return object.Params, nil
+}
+
+// addParamsXML returns the script object with the parameters added to its scriptcustomfields
+func addParamsXML(content string, params []*ScriptParam) (string, error) {
+	// Refuse parameters the object already declares
+	existing, err := readParams(content)
+	if err != nil {
+		return "", err
+	}
+	seen := map[string]bool{}
+	for _, param := range existing {
+		seen[param.ScriptId] = true
+	}
+	var b strings.Builder
+	for _, param := range params {
+		if seen[param.ScriptId] {
+			return "", fmt.Errorf("parameter %s is already declared", param.ScriptId)
+		}
+		seen[param.ScriptId] = true
+		b.WriteString(paramXML(param))
+	}
+
+	// Append to the existing list, or start one after the script file
+	if i := strings.Index(content, "  </scriptcustomfields>"); i >= 0 {
+		return content[:i] + b.String() + content[i:], nil
+	}
+	end := strings.Index(content, "</scriptfile>")
+	if end < 0 {
+		return "", fmt.Errorf("script object has no scriptfile")
+	}
+	end += len("</scriptfile>")
+	return content[:end] + "\n  <scriptcustomfields>\n" + strings.TrimSuffix(b.String(), "\n") + "\n  </scriptcustomfields>" + content[end:], nil
+}
+
+// paramXML returns the scriptcustomfield element of a parameter
+func paramXML(param *ScriptParam) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("    <scriptcustomfield scriptid=\"%s\">\n", escapeXML(param.ScriptId)))
+	b.WriteString(fmt.Sprintf("      <defaultvalue>%s</defaultvalue>\n", escapeXML(param.Default)))
+	b.WriteString("      <description></description>\n")
+	b.WriteString("      <displaytype>NORMAL</displaytype>\n")
+	b.WriteString(fmt.Sprintf("      <fieldtype>%s</fieldtype>\n", escapeXML(param.Type)))
+	b.WriteString("      <help></help>\n")
+	b.WriteString("      <ismandatory>F</ismandatory>\n")
+	b.WriteString(fmt.Sprintf("      <label>%s</label>\n", escapeXML(param.Label)))
+	b.WriteString(fmt.Sprintf("      <selectrecordtype>%s</selectrecordtype>\n", escapeXML(param.Source)))
+	b.WriteString("      <storevalue>T</storevalue>\n")
+	b.WriteString("    </scriptcustomfield>\n")
+	return b.String()
+}
+
+// paramsTS returns the typed parameter accessor for a script file. Both are
+// exported so a script that does not read them yet still compiles with
+// noUnusedLocals.
+func paramsTS(params []*ScriptParam) string {
+	var b strings.Builder
+	b.WriteString(paramsBegin + "\n")
+	b.WriteString("/** Script parameters */\nexport interface Params {\n")
+	for _, param := range params {
+		b.WriteString(fmt.Sprintf("    /** %s */\n    %s: %s;\n", docComment(param.Label), param.Key(), fieldTypes[param.Type]))
+	}
+	b.WriteString("}\n\n/** Read the parameters of the current script */\nexport function getParams(): Params {\n")
+	b.WriteString("    const script = runtime.getCurrentScript();\n    return {\n")
+	for _, param := range params {
+		b.WriteString(fmt.Sprintf("        %s: %s,\n", param.Key(), param.accessor()))
+	}
+	b.WriteString("    };\n}\n" + paramsEnd)
+	return b.String()
+}
+
+// setParamsTS returns the script file with its parameter accessor generated
+// or replaced, importing N/runtime when needed
+func setParamsTS(content string, params []*ScriptParam) (string, error) {
+	block := paramsTS(params)
+	// Replace the existing accessor
+	if begin := strings.Index(content, paramsBegin); begin >= 0 {
+		end := strings.Index(content, paramsEnd)
+		if end < begin {
+			return "", fmt.Errorf("parameter accessor has no %q marker", paramsEnd)
+		}
+		return content[:begin] + block + content[end+len(paramsEnd):], nil
+	}
+	// Import the runtime module
+	if !strings.Contains(content, `from "N/runtime"`) {
+		content = "import * as runtime from \"N/runtime\";\n" + content
+	}
+	// Add the accessor after the script header
+	header := strings.Index(content, "@NScriptType")
+	if header < 0 {
+		return "", fmt.Errorf("script file has no @NScriptType header")
+	}
+	end := strings.Index(content[header:], "*/")
+	if end < 0 {
+		return "", fmt.Errorf("script file header is not closed")
+	}
+	end += header + len("*/")
+	return content[:end] + "\n\n" + block + content[end:], nil
+}
+
+// applyParams adds parameters to a script object and regenerates the accessor of its script file
+func applyParams(xmlContent string, tsContent string, params []*ScriptParam) (string, string, error) {
+	xmlContent, err := addParamsXML(xmlContent, params)
+	if err != nil {
+		return "", "", err
+	}
+	all, err := readParams(xmlContent)
+	if err != nil {
+		return "", "", err
+	}
+	tsContent, err = setParamsTS(tsContent, all)
+	if err != nil {
+		return "", "", err
+	}
+	return xmlContent, tsContent, nil
+}
+
+// scriptObjectPath returns the object XML of a script file, named after it in src/Objects
+func (s *Tree) scriptObjectPath(scriptPath string) string {
+	name := strings.TrimSuffix(filepath.Base(scriptPath), filepath.Ext(scriptPath))
+	return filepath.Join(s.dirname, "src", "Objects", name+".xml")
+}
+
+// CreateParam adds a parameter to an existing script, in its object XML and
+// in the typed accessor of its script file
+func (s *Tree) CreateParam(global *store.GlobalStore, project *store.ProjectStore, script string, opts *ParamOptions) error {
+	// Find the script file and its object
+	if filepath.Ext(script) == "" {
+		script += ".ts"
+	}
+	scriptPath, err := s.resolveScript(global, project, script)
+	if err != nil {
+		return err
+	}
+	objectPath := s.scriptObjectPath(scriptPath)
+	if !util.Exists(objectPath) {
+		return fmt.Errorf("script object %s does not exist", objectPath)
+	}
+
+	// Get the parameter values from the options or the user
+	opts.Id, err = util.AskInput(opts.Id, "Enter the parameter id: ", "id", "", opts.Yes)
+	if err != nil {
+		return err
+	}
+	opts.Label, err = util.AskInput(opts.Label, "Enter the parameter label: ", "label", "", opts.Yes)
+	if err != nil {
+		return err
+	}
+	opts.Type, err = util.AskInput(opts.Type, "Enter the parameter type (default text): ", "type", "text", opts.Yes)
+	if err != nil {
+		return err
+	}
+	if opts.Source == "" && strings.HasSuffix(strings.ToUpper(opts.Type), "SELECT") && !opts.Yes && util.IsInteractive() {
+		opts.Source = util.GetInput("Enter the list or record source: ")
+	}
+	param, err := newParam(global, opts)
+	if err != nil {
+		return err
+	}
+
+	// Update both files before writing them together
+	xmlContent, err := os.ReadFile(objectPath)
+	if err != nil {
+		return err
+	}
+	tsContent, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return err
+	}
+	updatedXML, updatedTS, err := applyParams(string(xmlContent), string(tsContent), []*ScriptParam{param})
+	if err != nil {
+		return err
+	}
+	return s.atomically(func() error {
+		err := s.createFile(objectPath, updatedXML)
+		if err != nil {
+			return err
+		}
+		return s.createFile(scriptPath, updatedTS)
+	})
+}
@@ -0,0 +1,363 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RecordType represents a custom record type and its fields
+type RecordType struct {
+	// Company name
+	CompanyName string
+	// Date of record creation
+	Date string
+	// Description of record
+	Description string
+	// Project name
+	Project string
+	// User email
+	UserEmail string
+	// User name
+	UserName string
+	// Record name
+	RecordName string
+	// Record script ID
+	ScriptId string
+	// TypeScript accessor class name
+	ClassName string
+	// Record fields
+	Fields []*RecordField
+	// SuiteScript API version
+	ApiVersion string
+}
+
+// RecordField represents a custom record field
+type RecordField struct {
+	// Field script ID
+	ScriptId string
+	// TypeScript property name
+	Key string
+	// Field label
+	Label string
+	// SDF field type
+	Type string
+	// Whether the field is mandatory
+	Mandatory bool
+	// Source list or record for select fields
+	Source string
+	// TypeScript value type
+	TSType string
+}
+
+// RecordOptions holds values for a record that would otherwise be prompted for
+type RecordOptions struct {
+	// Record name
+	Name string
+	// Description of record
+	Description string
+	// Record script ID override
+	ScriptId string
+	// Field definitions as id:label:type[:mandatory[:source]]
+	Fields []string
+	// Use defaults instead of prompting
+	Yes bool
+}
+
+// fieldTypes maps SDF field types to their TypeScript value types
+var fieldTypes = map[string]string{
+	"CHECKBOX":    "boolean",
+	"CLOBTEXT":    "string",
+	"CURRENCY":    "number",
+	"DATE":        "Date",
+	"DATETIMETZ":  "Date",
+	"DOCUMENT":    "string",
+	"EMAIL":       "string",
+	"FLOAT":       "number",
+	"IMAGE":       "string",
+	"INLINEHTML":  "string",
+	"INTEGER":     "number",
+	"MULTISELECT": "string[]",
+	"PASSWORD":    "string",
+	"PERCENT":     "number",
+	"PHONE":       "string",
+	"RICHTEXT":    "string",
+	"SELECT":      "string",
+	"TEXT":        "string",
+	"TEXTAREA":    "string",
+	"TIMEOFDAY":   "Date",
+	"URL":         "string",
+}
+
+// CreateRecord creates a custom record type object and its TypeScript accessor
+func (s *Tree) CreateRecord(global *store.GlobalStore, project *store.ProjectStore, opts *RecordOptions) error {
+	// Get the record name from the options or the user
+	recordName, err := util.AskInput(opts.Name, "Enter the record name: ", "name", "", opts.Yes)
+	if err != nil {
+		return err
+	}
+	// Get the record description from the options or the user
+	description, err := util.AskInput(opts.Description, "Enter the record description: ", "description", "No description", opts.Yes)
+	if err != nil {
+		return err
+	}
+
+	// Parse the field definitions, or ask for them
+	var fields []*RecordField
+	if len(opts.Fields) > 0 {
+		fields, err = parseRecordFields(global, opts.Fields)
+	} else if !opts.Yes && util.IsInteractive() {
+		fields, err = collectRecordFields(global)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Get the API version of the project
+	version, err := apiVersion(project, "")
+	if err != nil {
+		return err
+	}
+	// Create a file pattern using the vendor prefix and record name
+	filePattern := vendorPattern(global, recordName)
+	// Create a new record type
+	recordType := &RecordType{
+		CompanyName: global.VendorName,
+		Date:        time.Now().Format("01/02/2006"),
+		Description: description,
+		Project:     project.Current,
+		UserEmail:   global.AuthorEmail,
+		UserName:    global.AuthorName,
+		RecordName:  recordName,
+		ScriptId:    withPrefix(opts.ScriptId, "customrecord_", filePattern),
+		ClassName:   pascalCase(recordName),
+		Fields:      fields,
+		ApiVersion:  version,
+	}
+	if recordType.ClassName == "" {
+		return fmt.Errorf("record name %q has no letters or digits to name its class", recordName)
+	}
+
+	// Look up and parse the xml object
+	xml, _, err := s.lookupTemplate("record.xml")
+	if err != nil {
+		return err
+	}
+	parsedXML, err := s.parseTemplate(recordType, "record", xml)
+	if err != nil {
+		return err
+	}
+
+	// Look up and parse the typescript accessor
+	ts, _, err := s.lookupTemplate("record.ts")
+	if err != nil {
+		return err
+	}
+	parsedTS, err := s.parseTemplate(recordType, "record", ts)
+	if err != nil {
+		return err
+	}
+
+	// Create the object and its accessor together
+	folder, err := projectPath(global, project)
+	if err != nil {
+		return err
+	}
+	tsPath, err := folder.Join(fmt.Sprintf("%s_record.ts", filePattern))
+	if err != nil {
+		return err
+	}
+	return s.atomically(func() error {
+		err := s.createFile(filepath.Join(s.dirname, "src", "Objects", recordType.ScriptId+".xml"), parsedXML)
+		if err != nil {
+			return err
+		}
+		return s.createFile(tsPath.Local(s.dirname), parsedTS)
+	})
+}
+
+// parseRecordFields parses field definitions given as id:label:type[:mandatory[:source]]
+func parseRecordFields(global *store.GlobalStore, definitions []string) ([]*RecordField, error) {
+	var fields []*RecordField
+	for _, definition := range definitions {
+		parts := splitDefinition(definition, 5)
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("invalid field %q, expected id:label:type[:mandatory[:source]]", definition)
+		}
+		mandatory := len(parts) > 3 && isTrue(parts[3])
+		source := ""
+		if len(parts) > 4 {
+			source = parts[4]
+		}
+		field, err := newRecordField(global, parts[0], parts[1], parts[2], mandatory, source)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, checkRecordFields(fields)
+}
+
+// collectRecordFields prompts the user for fields until a blank id is given
+func collectRecordFields(global *store.GlobalStore) ([]*RecordField, error) {
+	var fields []*RecordField
+	for {
+		// Get the field id, a blank id ends the list
+		id := util.GetInput("Enter the field id (leave blank to finish): ")
+		if id == "" {
+			break
+		}
+		label := util.GetInput("Enter the field label: ")
+		fieldType := util.GetInput("Enter the field type (text, checkbox, date, select, ...): ")
+		mandatory := isTrue(util.GetInput("Is the field mandatory? (y/N): "))
+		source := ""
+		if strings.HasSuffix(strings.ToUpper(fieldType), "SELECT") {
+			source = util.GetInput("Enter the list or record source: ")
+		}
+		field, err := newRecordField(global, id, label, fieldType, mandatory, source)
+		if err != nil {
+			// Ask again for an invalid field
+			fmt.Println(err)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields, checkRecordFields(fields)
+}
+
+// newRecordField creates a record field, prefixing its id with custrecord_ and the vendor prefix
+func newRecordField(global *store.GlobalStore, id string, label string, fieldType string, mandatory bool, source string) (*RecordField, error) {
+	// Check the field id and label
+	id = strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(id, "custrecord_")), " ", "_")
+	id = strings.TrimPrefix(id, global.VendorPrefix+"_")
+	if id == "" {
+		return nil, fmt.Errorf("field id must be non-empty")
+	}
+	if !fieldIdPattern.MatchString(id) {
+		return nil, fmt.Errorf("field id %q may only contain a-z, 0-9 and _", id)
+	}
+	if label == "" {
+		return nil, fmt.Errorf("label of field %s must be non-empty", id)
+	}
+	// Check the field type and source
+	fieldType, source, err := checkFieldType(id, fieldType, source)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordField{
+		ScriptId:  fmt.Sprintf("custrecord_%s_%s", global.VendorPrefix, id),
+		Key:       camelCase(id),
+		Label:     label,
+		Type:      fieldType,
+		Mandatory: mandatory,
+		Source:    source,
+		TSType:    fieldTypes[fieldType],
+	}, nil
+}
+
+// checkFieldType returns the SDF field type and source reference, ensuring
+// select fields have a source and other fields do not
+func checkFieldType(id string, fieldType string, source string) (string, string, error) {
+	// Check the field type
+	fieldType = strings.ToUpper(fieldType)
+	if _, ok := fieldTypes[fieldType]; !ok {
+		return "", "", fmt.Errorf("unknown type %q for field %s", fieldType, id)
+	}
+	// Check the source of select fields
+	if strings.HasSuffix(fieldType, "SELECT") {
+		if source == "" {
+			return "", "", fmt.Errorf("field %s of type %s needs a source", id, fieldType)
+		}
+		// Custom lists and records are referenced by script id
+		if isCustomObjectId(source) {
+			source = fmt.Sprintf("[%s]", strings.ToLower(strings.Trim(source, "[]")))
+		}
+	} else if source != "" {
+		return "", "", fmt.Errorf("field %s of type %s cannot have a source", id, fieldType)
+	}
+	return fieldType, source, nil
+}
+
+// customObjectPrefixes lists the script id prefixes of custom objects. Standard
+// ids such as customer or customerpayment also start with "custom" and are not
+// references.
+var customObjectPrefixes = []string{
+	"customlist", "customrecord", "customrole", "customsearch", "customsublist", "customtransaction",
+}
+
+// isCustomObjectId checks if a value is the script id of a custom object,
+// which SDF references as [scriptid]
+func isCustomObjectId(value string) bool {
+	value = strings.ToLower(strings.Trim(strings.TrimSpace(value), "[]"))
+	for _, prefix := range customObjectPrefixes {
+		// Ids are the prefix followed by _name, or by a number for objects made in the UI
+		rest, ok := strings.CutPrefix(value, prefix)
+		if ok && rest != "" && (rest[0] == '_' || unicode.IsDigit(rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedKeys lists the members of the generated accessor class, which field
+// properties must not hide
+var reservedKeys = []string{"__proto__", "constructor", "create", "load", "name", "prototype", "rec", "save"}
+
+// checkRecordFields ensures property names are unique and do not hide a member of the accessor
+func checkRecordFields(fields []*RecordField) error {
+	seen := map[string]bool{}
+	for _, key := range reservedKeys {
+		seen[key] = true
+	}
+	for _, field := range fields {
+		if field.Key == "" {
+			return fmt.Errorf("field %s has no letters or digits to name its property", field.ScriptId)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("field %s clashes with another property named %s", field.ScriptId, field.Key)
+		}
+		seen[field.Key] = true
+	}
+	return nil
+}
+
+// isTrue checks if a value reads as yes
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "y", "yes", "t", "true", "1", "mandatory":
+		return true
+	}
+	return false
+}
+
+// camelCase converts an underscore or space separated name to camelCase
+func camelCase(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+		if i > 0 {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	// Identifiers cannot start with a digit
+	key := strings.Join(words, "")
+	if key != "" && unicode.IsDigit(rune(key[0])) {
+		key = "_" + key
+	}
+	return key
+}
+
+// pascalCase converts an underscore or space separated name to PascalCase
+func pascalCase(name string) string {
+	key := camelCase(name)
+	if key == "" {
+		return key
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
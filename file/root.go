@@ -1,7 +1,7 @@
 package file
 
 import (
-	"log"
+	"netsuite-companion/store"
 	"os"
 	"path/filepath"
 )
@@ -9,46 +9,46 @@ import (
 // Tree represents a file tree structure
 type Tree struct {
 	dirname string
+	// Transaction holding the files of the running operation
+	txn *transaction
+	// Backend holding the inference API key
+	secrets store.SecretBackend
 }
 
-// CreateTree creates a new Tree instance
-func CreateTree() *Tree {
-	dirname, err := os.Getwd()
-	if err != nil {
-		log.Fatalln(err)
-	}
-	return &Tree{dirname}
+// CreateTree creates a new Tree instance for the project at dirname
+func CreateTree(dirname string) *Tree {
+	return &Tree{dirname: dirname}
 }
 
-// Build builds the file tree structure
-func (s *Tree) Build() error {
-	err := os.MkdirAll(filepath.Join(s.dirname, "src", "AccountConfiguration"), os.ModePerm)
-	if err != nil {
-		return err
-	}
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "SuiteScripts"), os.ModePerm)
-	if err != nil {
-		return err
-	}
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Templates"), os.ModePerm)
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Templates", "E-mail Templates"), os.ModePerm)
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Templates", "Marketing Templates"), os.ModePerm)
-	if err != nil {
-		return err
-	}
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Web Site Hosting Files"), os.ModePerm)
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Web Site Hosting Files", "Live Hosting Files"), os.ModePerm)
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "FileCabinet", "Web Site Hosting Files", "Staging Hosting Files"), os.ModePerm)
+// SetSecrets sets the backend holding secrets such as the inference API key
+func (s *Tree) SetSecrets(secrets store.SecretBackend) {
+	s.secrets = secrets
+}
+
+// Build builds the file tree structure, with a tsconfig.json for the given API version
+func (s *Tree) Build(apiVersion string) (err error) {
+	// Create the tree in a single transaction
+	txn, err := s.begin()
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "Objects"), os.ModePerm)
-	if err != nil {
-		return err
+	defer s.finish(txn, &err)
+
+	dirs := [][]string{
+		{"src", "AccountConfiguration"},
+		{"src", "FileCabinet", "SuiteScripts"},
+		{"src", "FileCabinet", "Templates", "E-mail Templates"},
+		{"src", "FileCabinet", "Templates", "Marketing Templates"},
+		{"src", "FileCabinet", "Web Site Hosting Files", "Live Hosting Files"},
+		{"src", "FileCabinet", "Web Site Hosting Files", "Staging Hosting Files"},
+		{"src", "Objects"},
+		{"src", "Translations"},
 	}
-	err = os.MkdirAll(filepath.Join(s.dirname, "src", "Translations"), os.ModePerm)
-	if err != nil {
-		return err
+	for _, dir := range dirs {
+		err = s.mkdir(filepath.Join(append([]string{s.dirname}, dir...)...))
+		if err != nil {
+			return err
+		}
 	}
 
 	err = s.createFile(filepath.Join(s.dirname, "src", "deploy.xml"), `<deploy>
@@ -70,6 +70,8 @@ func (s *Tree) Build() error {
 	}
 	err = s.createFile(filepath.Join(s.dirname, ".gitignore"), `.idea
 node_modules
+.nsc-backup
+.nsc-txn-*
 	`)
 	if err != nil {
 		return err
@@ -86,52 +88,19 @@ node_modules
 	if err != nil {
 		return err
 	}
-	err = s.createFile(filepath.Join(s.dirname, "tsconfig.json"), `{
-  "compilerOptions": {
-    "target": "es5",
-    "module": "umd",
-    "moduleResolution": "node",
-    "sourceMap": false,
-    "newLine": "LF",
-    "experimentalDecorators": true,
-    "noImplicitAny": true,
-    "noImplicitThis": true,
-    "strictNullChecks": true,
-    "strictFunctionTypes": true,
-    "strictPropertyInitialization": true,
-    "baseUrl": "./",
-    "noUnusedLocals": true,
-    "noUnusedParameters": true,
-    "noImplicitReturns": true,
-    "noFallthroughCasesInSwitch": true,
-    "lib": [
-      "es5",
-      "es2015.promise",
-      "dom"
-    ],
-    "paths": {
-      "N": [
-        "node_modules/@hitc/netsuite-types/N"
-      ],
-      "N/*": [
-        "node_modules/@hitc/netsuite-types/N/*"
-      ]
-    }
-  },
-  "include": [
-    "src"
-  ]
-}`)
+	err = s.createFile(filepath.Join(s.dirname, "tsconfig.json"), tsConfig(apiVersion))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// createFile creates a new file
+// createFile creates a new file, or holds it back in a transaction
 func (s *Tree) createFile(destination string, content string) error {
-	gitignoreContent := []byte(content)
-	err := os.WriteFile(destination, gitignoreContent, os.ModePerm)
+	if s.txn != nil {
+		return s.txn.write(destination, content)
+	}
+	err := os.WriteFile(destination, []byte(content), 0644)
 	if err != nil {
 		return err
 	}
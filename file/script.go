@@ -2,12 +2,12 @@ package file
 
 import (
 	"bytes"
-	"context"
+	"encoding/xml"
 	"fmt"
-	"github.com/sashabaranov/go-openai"
 	"netsuite-companion/store"
 	"netsuite-companion/util"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -31,24 +31,141 @@ type ClientScript struct {
 	ScriptName string
 	// Script ID
 	ScriptId string
-	// Path to script file
-	ScriptPath string
+	// FileCabinet path of the compiled script file
+	ScriptPath CabinetPath
 	// Deployment ID
 	DeploymentId string
+	// SuiteScript API version
+	ApiVersion string
+	// Entry points to render, all of them when empty
+	Entries []string
+	// Script deployments
+	Deployments []*Deployment
 }
 
-// parseTemplate parses a template and replaces placeholders with script data
-func (s *Tree) parseTemplate(script *ClientScript, name string, text string) (string, error) {
+// Entry checks if an entry point is rendered
+func (c *ClientScript) Entry(name string) bool {
+	return len(c.Entries) == 0 || slices.Contains(c.Entries, name)
+}
+
+// Modern checks if the script targets SuiteScript 2.1. Only the entry point
+// declarations and RESTlet exports differ, the rest of the template bodies
+// is shared between versions.
+func (c *ClientScript) Modern() bool {
+	return c.ApiVersion == "2.1"
+}
+
+// Declare returns the keyword declaring exported entry points
+func (c *ClientScript) Declare() string {
+	if c.Modern() {
+		return "const"
+	}
+	return "let"
+}
+
+// ScriptOptions holds values for a script that would otherwise be prompted for
+type ScriptOptions struct {
+	// File name
+	Name string
+	// Description of script
+	Description string
+	// Script ID override
+	ScriptId string
+	// Deployment ID override
+	DeploymentId string
+	// SuiteScript API version override
+	ApiVersion string
+	// Entry points to generate
+	Entries []string
+	// Overwrite existing files without asking
+	Force bool
+	// Deployment settings, one entry per group of deployments
+	Deployments []DeploymentOptions
+	// Script parameters as id:label:type[:default[:source]]
+	Params []string
+	// Instructions for the inference service
+	Instruct string
+	// File holding instructions for the inference service
+	InstructFile string
+	// Inference model override
+	Model string
+	// Inference provider override
+	Provider string
+	// Send project files as context to the inference service
+	Context bool
+	// Extra files sent as context to the inference service
+	ContextFiles []string
+	// Skip the inference response cache
+	NoCache bool
+	// Show the template and model output before writing
+	Preview bool
+	// Use defaults instead of prompting
+	Yes bool
+}
+
+// withPrefix returns the id override with its prefix, or the prefixed default pattern
+func withPrefix(id string, prefix string, pattern string) string {
+	// Use the default pattern when no override is set
+	if id == "" {
+		return prefix + pattern
+	}
+	// Ensure the override carries the object prefix
+	if !strings.HasPrefix(id, prefix) {
+		return prefix + id
+	}
+	return id
+}
+
+// vendorPattern joins the vendor prefix and the file name made lowercase with spaces replaced by underscores
+func vendorPattern(global *store.GlobalStore, name string) string {
+	return fmt.Sprintf("%s_%s", global.VendorPrefix, strings.ReplaceAll(strings.ToLower(name), " ", "_"))
+}
+
+// projectPath returns the FileCabinet folder of the current project
+func projectPath(global *store.GlobalStore, project *store.ProjectStore) (CabinetPath, error) {
+	return NewCabinetPath("SuiteScripts", global.VendorName, project.Current)
+}
+
+// apiVersion returns the API version override, or the current project default
+func apiVersion(project *store.ProjectStore, override string) (string, error) {
+	if override != "" {
+		if !slices.Contains(store.ApiVersions(), override) {
+			return "", fmt.Errorf("unknown api version %s, expected one of %v", override, store.ApiVersions())
+		}
+		// The project folder compiles every script with the same options
+		if tsOptionsFor(override).Target != tsOptionsFor(project.ApiVersion()).Target {
+			return "", fmt.Errorf("project %s compiles for SuiteScript %s, a %s script needs a project of its own",
+				project.Current, project.ApiVersion(), override)
+		}
+		return override, nil
+	}
+	return project.ApiVersion(), nil
+}
+
+// escapeXML escapes a value for XML text and attributes
+func escapeXML(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// docComment keeps a value from closing the /** */ comment it is written in
+func docComment(value string) string {
+	return strings.ReplaceAll(value, "*/", "*\\/")
+}
+
+// parseTemplate parses a template and replaces placeholders with the given data
+func (s *Tree) parseTemplate(data interface{}, name string, text string) (string, error) {
 	// Create a new template with the given name
-	t, err := template.New(name).Parse(text)
+	t, err := template.New(name).Funcs(template.FuncMap{"xml": escapeXML, "doc": docComment}).Parse(text)
 	if err != nil {
 		// Return an error if the template cannot be parsed
 		return "", err
 	}
 	// Create a bytes buffer to store the result
 	var result bytes.Buffer
-	// Execute the template with the script data
-	err = t.Execute(&result, script)
+	// Execute the template with the data
+	err = t.Execute(&result, data)
 	if err != nil {
 		// Return an error if the template execution fails
 		return "", err
@@ -57,177 +174,196 @@ func (s *Tree) parseTemplate(script *ClientScript, name string, text string) (st
 	return result.String(), nil
 }
 
-func (s *Tree) runInference(global *store.GlobalStore, instruct string, parsedTS string) (string, error) {
-	if global.OpenAIApiKey == "" {
-		return "", fmt.Errorf("openai_api_key not set on global config")
-	}
-	client := openai.NewClient(global.OpenAIApiKey)
-	resp, err := client.CreateChatCompletion(
-		context.Background(),
-		openai.ChatCompletionRequest{
-			Model: openai.GPT4,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: instruct,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: parsedTS,
-				},
-			},
-		},
-	)
+// addDeploymentFiles adds deployment files for a script
+func (s *Tree) addDeploymentFiles(global *store.GlobalStore, project *store.ProjectStore, scriptType string, opts *ScriptOptions) error {
+	// Look up the typescript and xml templates for the script type
+	ts, _, err := s.lookupTemplate(scriptType + ".ts")
 	if err != nil {
-		return "", err
+		return err
+	}
+	xml, _, err := s.lookupTemplate(scriptType + ".xml")
+	if err != nil {
+		return err
 	}
-	return resp.Choices[0].Message.Content, nil
-}
 
-// addDeploymentFiles adds deployment files for a script
-func (s *Tree) addDeploymentFiles(global *store.GlobalStore, project *store.ProjectStore, scriptType string, ts string, xml string, instruct string) error {
 	// Check if either typescript or xml content is set
 	if ts == "" && xml == "" {
 		// Return an error if neither is set
 		return fmt.Errorf("typescript or xml content must be set")
 	}
 
-	// Get the file name from the user
-	var fileName string
-	for fileName = ""; fileName == ""; {
-		// Get the file name from the user
-		fileName = util.GetInput("Enter the file name: ")
-		if fileName == "" {
-			// If the file name is blank, ask again
-			continue
-		}
-		break
+	// Get the file name from the options or the user
+	fileName, err := util.AskInput(opts.Name, "Enter the file name: ", "name", "", opts.Yes)
+	if err != nil {
+		return err
 	}
 
-	// Parse the file name to make it lowercase and replace spaces with underscores
-	fileNameParsed := strings.ReplaceAll(strings.ToLower(fileName), " ", "_")
-	// Get the file description from the user
-	var description = util.GetInput("Enter the file description: ")
-	if description == "" {
-		description = "No description"
-	}
-	// Create a file pattern using the vendor prefix and file name
-	filePattern := fmt.Sprintf("%s_%s", global.VendorPrefix, fileNameParsed)
+	// Get the file description from the options or the user
+	description, err := util.AskInput(opts.Description, "Enter the file description: ", "description", "No description", opts.Yes)
+	if err != nil {
+		return err
+	}
+	// Get the API version from the options or the project
+	version, err := apiVersion(project, opts.ApiVersion)
+	if err != nil {
+		return err
+	}
+	// Get the entry points from the options or the user
+	entries, err := selectEntryPoints(scriptType, opts)
+	if err != nil {
+		return err
+	}
+	// Get the deployment options once, as they do not depend on the file name
+	deployments, err := deploymentOptions(scriptType, opts)
+	if err != nil {
+		return err
+	}
 	// Create the project path
-	projectPath := filepath.Join("SuiteScripts", global.VendorName, project.Current)
-	// Create the script path
-	scriptPath := filepath.Join(projectPath, fmt.Sprintf("%s_%s.js", filePattern, scriptType))
-	// Create a new client script
-	clientScript := &ClientScript{
-		CompanyName:  global.VendorName,
-		Date:         time.Now().Format("01/02/2006"),
-		Description:  description,
-		Project:      project.Current,
-		UserEmail:    global.AuthorEmail,
-		UserName:     global.AuthorName,
-		ScriptName:   fileName,
-		ScriptId:     fmt.Sprintf(`customscript_%s`, filePattern),
-		ScriptPath:   fmt.Sprintf(`\%s`, scriptPath),
-		DeploymentId: fmt.Sprintf(`customdeploy_%s`, filePattern),
-	}
-	// If typescript content is set, parse the template and create a file
-	if ts != "" {
-		parsedTS, err := s.parseTemplate(clientScript, scriptType, ts)
+	projectPath, err := projectPath(global, project)
+	if err != nil {
+		return err
+	}
+	// Build the script, asking for another name while it clashes with existing work
+	var clientScript *ClientScript
+	var tsPath, xmlPath string
+	for {
+		// Create a file pattern using the vendor prefix and file name
+		filePattern := vendorPattern(global, fileName)
+		// Create the script path
+		scriptPath, err := projectPath.Join(fmt.Sprintf("%s_%s.js", filePattern, scriptType))
 		if err != nil {
-			// Return an error if the template parsing fails
 			return err
 		}
-		if instruct != "" {
-			parsedTS, err = s.runInference(global, instruct, parsedTS)
+		// Create a new client script
+		clientScript = &ClientScript{
+			CompanyName:  global.VendorName,
+			Date:         time.Now().Format("01/02/2006"),
+			Description:  description,
+			Project:      project.Current,
+			UserEmail:    global.AuthorEmail,
+			UserName:     global.AuthorName,
+			ScriptName:   fileName,
+			ScriptId:     withPrefix(opts.ScriptId, "customscript_", filePattern),
+			ScriptPath:   scriptPath,
+			DeploymentId: withPrefix(opts.DeploymentId, "customdeploy_", filePattern),
+			ApiVersion:   version,
+			Entries:      entries,
+		}
+		// Build the deployments from the options
+		clientScript.Deployments, err = buildDeployments(scriptType, deployments, clientScript.DeploymentId, fileName)
+		if err != nil {
+			return err
+		}
+		// Get the paths of the files to create
+		tsPath, xmlPath = "", ""
+		if ts != "" {
+			tsPath = scriptPath.WithExt(".ts").Local(s.dirname)
+		}
+		if xml != "" {
+			xmlPath = filepath.Join(s.dirname, "src", "Objects", fmt.Sprintf("%s_%s.xml", filePattern, scriptType))
+		}
+
+		// Check the files and ids are free
+		ids := []string{}
+		if xml != "" {
+			ids = append(ids, clientScript.ScriptId)
+			for _, d := range clientScript.Deployments {
+				ids = append(ids, d.ScriptId)
+			}
+		}
+		clash, err := s.checkCollisions([]string{tsPath, xmlPath}, ids)
+		if err != nil {
+			return err
+		}
+		if clash == nil {
+			break
+		}
+		action, err := s.resolveCollision(clash, opts)
+		if err != nil {
+			return err
+		}
+		if action == collisionSkip {
+			fmt.Printf("Skipped %s\n", fileName)
+			return nil
+		}
+		if action == collisionOverwrite {
+			err = s.backupFiles(clash.files...)
 			if err != nil {
-				// Return an error if the inference fails
 				return err
 			}
+			break
 		}
-
-		err = s.createFile(filepath.Join(
-			filepath.Join(s.dirname, "src", "FileCabinet", projectPath),
-			fmt.Sprintf("%s_%s.ts", filePattern, scriptType),
-		), parsedTS)
+		// Ask for a new name, dropping id overrides that would clash again
+		fileName, err = util.AskInput("", "Enter a new file name: ", "name", "", false)
 		if err != nil {
-			// Return an error if the file creation fails
 			return err
 		}
+		opts.ScriptId, opts.DeploymentId = "", ""
 	}
-	// If xml content is set, parse the template and create a file
+	// If xml content is set, parse the template
+	var parsedXML string
 	if xml != "" {
-		parsedXML, err := s.parseTemplate(clientScript, scriptType, xml)
+		parsedXML, err = s.parseTemplate(clientScript, scriptType, xml)
+		if err != nil {
+			// Return an error if the template parsing fails
+			return err
+		}
+	}
+	// If typescript content is set, parse the template
+	var parsedTS string
+	if ts != "" {
+		parsedTS, err = s.parseTemplate(clientScript, scriptType, ts)
 		if err != nil {
 			// Return an error if the template parsing fails
 			return err
 		}
-		err = s.createFile(filepath.Join(
-			filepath.Join(s.dirname, "src", "Objects"),
-			fmt.Sprintf("%s_%s.xml", filePattern, scriptType),
-		), parsedXML)
+	}
+	// Add the script parameters to both files
+	if len(opts.Params) > 0 {
+		if parsedXML == "" || parsedTS == "" {
+			return fmt.Errorf("%s files have no script parameters", scriptType)
+		}
+		params, err := parseParams(global, opts.Params)
+		if err != nil {
+			return err
+		}
+		parsedXML, parsedTS, err = applyParams(parsedXML, parsedTS, params)
 		if err != nil {
-			// Return an error if the file creation fails
 			return err
 		}
 	}
-	// Return nil if no errors occurred
-	return nil
+	// Run the typescript file through the inference provider
+	if parsedTS != "" && opts.instructed() {
+		parsedTS, err = s.inferScript(global, project, opts, parsedTS)
+		if err != nil {
+			// Return an error if the inference fails or is declined
+			return err
+		}
+	}
+	// Create the typescript and xml files together
+	return s.atomically(func() error {
+		if parsedTS != "" {
+			err := s.createFile(tsPath, parsedTS)
+			if err != nil {
+				// Return an error if the file creation fails
+				return err
+			}
+		}
+		if parsedXML != "" {
+			err := s.createFile(xmlPath, parsedXML)
+			if err != nil {
+				// Return an error if the file creation fails
+				return err
+			}
+		}
+		// Return nil if no errors occurred
+		return nil
+	})
 }
 
 // CreateBundle creates a bundle script
-func (s *Tree) CreateBundle(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "bundle", `import {EntryPoints} from "N/types";
-import onAfterInstallContext = EntryPoints.BundleInstallation.onAfterInstallContext;
-import onAfterUpdateContext = EntryPoints.BundleInstallation.onAfterUpdateContext;
-import onBeforeInstallContext = EntryPoints.BundleInstallation.onBeforeInstallContext;
-import onBeforeUpdateContext = EntryPoints.BundleInstallation.onBeforeUpdateContext;
-import onBeforeUninstallContext = EntryPoints.BundleInstallation.onBeforeUninstallContext;
-
-/**
- * Bundle Installation script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType BundleInstallationScript
- */
-
-/** afterInstall event handler */
-export let afterInstall: EntryPoints.BundleInstallation.afterInstall = (context: onAfterInstallContext) => {
-    // Enter code here
-};
-
-/** afterUpdate event handler */
-export let afterUpdate: EntryPoints.BundleInstallation.afterUpdate = (context: onAfterUpdateContext) => {
-    // Enter code here
-};
-
-/** beforeInstall event handler */
-export let beforeInstall: EntryPoints.BundleInstallation.beforeInstall = (context: onBeforeInstallContext) => {
-    // Enter code here
-};
-
-/** beforeUninstall event handler */
-export let beforeUninstall: EntryPoints.BundleInstallation.beforeUninstall = (context: onBeforeUninstallContext) => {
-    // Enter code here
-};
-
-/** beforeUpdate event handler */
-export let beforeUpdate: EntryPoints.BundleInstallation.beforeUpdate = (context: onBeforeUpdateContext) => {
-    // Enter code here
-};
-`, ``, instruct)
+func (s *Tree) CreateBundle(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "bundle", opts)
 	if err != nil {
 		return err
 	}
@@ -235,88 +371,8 @@ export let beforeUpdate: EntryPoints.BundleInstallation.beforeUpdate = (context:
 }
 
 // CreateClient creates a client script
-func (s *Tree) CreateClient(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "client", `import {EntryPoints} from "N/types";
-
-/**
- * Client script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType ClientScript
- */
-
-/** pageInit event handler */
-export let pageInit: EntryPoints.Client.pageInit = (context: EntryPoints.Client.pageInitContext) => {
-    // Enter code here
-};
-
-/** validateField event handler */
-export let validateField: EntryPoints.Client.validateField = (context: EntryPoints.Client.validateFieldContext) => {
-    // Enter code here
-};
-
-/** fieldChanged event handler */
-export let fieldChanged: EntryPoints.Client.fieldChanged = (context: EntryPoints.Client.fieldChangedContext) => {
-    // Enter code here
-};
-
-/** postSourcing event handler */
-export let postSourcing: EntryPoints.Client.postSourcing = (context: EntryPoints.Client.postSourcingContext) => {
-    // Enter code here
-};
-
-/** lineInit event handler */
-export let lineInit: EntryPoints.Client.lineInit = (context: EntryPoints.Client.lineInitContext) => {
-    // Enter code here
-};
-
-/** validateLine event handler */
-export let validateLine: EntryPoints.Client.validateLine = (context: EntryPoints.Client.validateLineContext) => {
-    // Enter code here
-};
-
-/** validateInsert event handler */
-export let validateInsert: EntryPoints.Client.validateInsert = (context: EntryPoints.Client.validateInsertContext) => {
-    // Enter code here
-};
-
-/** validateDelete event handler */
-export let validateDelete: EntryPoints.Client.validateDelete = (context: EntryPoints.Client.validateDeleteContext) => {
-    // Enter code here
-};
-
-/** sublistChanged event handler */
-export let sublistChanged: EntryPoints.Client.sublistChanged = (context: EntryPoints.Client.sublistChangedContext) => {
-    // Enter code here
-};
-
-/** saveRecord event handler */
-export let saveRecord: EntryPoints.Client.saveRecord = (context: EntryPoints.Client.saveRecordContext) => {
-    // Enter code here
-};
-`, `<clientscript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-</clientscript>`, instruct)
+func (s *Tree) CreateClient(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "client", opts)
 	if err != nil {
 		return err
 	}
@@ -324,77 +380,8 @@ export let saveRecord: EntryPoints.Client.saveRecord = (context: EntryPoints.Cli
 }
 
 // CreateFormClient creates a form client script
-func (s *Tree) CreateFormClient(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "formclient", `import {EntryPoints} from "N/types";
-
-/**
- * Form client script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType ClientScript
- */
-
-/** pageInit event handler */
-export let pageInit: EntryPoints.Client.pageInit = (context: EntryPoints.Client.pageInitContext) => {
-    // Enter code here
-};
-
-/** validateField event handler */
-export let validateField: EntryPoints.Client.validateField = (context: EntryPoints.Client.validateFieldContext) => {
-    // Enter code here
-};
-
-/** fieldChanged event handler */
-export let fieldChanged: EntryPoints.Client.fieldChanged = (context: EntryPoints.Client.fieldChangedContext) => {
-    // Enter code here
-};
-
-/** postSourcing event handler */
-export let postSourcing: EntryPoints.Client.postSourcing = (context: EntryPoints.Client.postSourcingContext) => {
-    // Enter code here
-};
-
-/** lineInit event handler */
-export let lineInit: EntryPoints.Client.lineInit = (context: EntryPoints.Client.lineInitContext) => {
-    // Enter code here
-};
-
-/** validateLine event handler */
-export let validateLine: EntryPoints.Client.validateLine = (context: EntryPoints.Client.validateLineContext) => {
-    // Enter code here
-};
-
-/** validateInsert event handler */
-export let validateInsert: EntryPoints.Client.validateInsert = (context: EntryPoints.Client.validateInsertContext) => {
-    // Enter code here
-};
-
-/** validateDelete event handler */
-export let validateDelete: EntryPoints.Client.validateDelete = (context: EntryPoints.Client.validateDeleteContext) => {
-    // Enter code here
-};
-
-/** sublistChanged event handler */
-export let sublistChanged: EntryPoints.Client.sublistChanged = (context: EntryPoints.Client.sublistChangedContext) => {
-    // Enter code here
-};
-
-/** saveRecord event handler */
-export let saveRecord: EntryPoints.Client.saveRecord = (context: EntryPoints.Client.saveRecordContext) => {
-    // Enter code here
-};
-`, ``, instruct)
+func (s *Tree) CreateFormClient(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "formclient", opts)
 	if err != nil {
 		return err
 	}
@@ -402,58 +389,8 @@ export let saveRecord: EntryPoints.Client.saveRecord = (context: EntryPoints.Cli
 }
 
 // CreateMapReduce creates a map/reduce script
-func (s *Tree) CreateMapReduce(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "mapreduce", `import {EntryPoints} from "N/types";
-
-/**
- * Map/Reduce script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType MapReduceScript
- */
-
-/** getInputData event handler */
-export let getInputData: EntryPoints.MapReduce.getInputData = (context: EntryPoints.MapReduce.getInputDataContext) => {
-    // Enter code here
-};
-
-/** map event handler */
-export let map: EntryPoints.MapReduce.map = (context: EntryPoints.MapReduce.mapContext) => {
-    // Enter code here
-};
-
-/** reduce event handler */
-export let reduce: EntryPoints.MapReduce.reduce = (context: EntryPoints.MapReduce.reduceContext) => {
-    // Enter code here
-};
-
-/** summarize event handler */
-export let summarize: EntryPoints.MapReduce.summarize = (summary: EntryPoints.MapReduce.summarizeContext) => {
-    // Enter code here
-};
-`, `<mapreducescript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-</mapreducescript>
-`, instruct)
+func (s *Tree) CreateMapReduce(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "mapreduce", opts)
 	if err != nil {
 		return err
 	}
@@ -461,43 +398,8 @@ export let summarize: EntryPoints.MapReduce.summarize = (summary: EntryPoints.Ma
 }
 
 // CreateMassUpdate creates a mass update script
-func (s *Tree) CreateMassUpdate(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "massupdate", `import {EntryPoints} from "N/types";
-
-/**
- * Mass Update script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType MassUpdateScript
- */
-
-/** each event handler */
-export let each: EntryPoints.MassUpdate.each = (params: EntryPoints.MassUpdate.eachContext) => {
-    // Enter code here
-};
-`, `<massupdatescript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-</massupdatescript>`, instruct)
+func (s *Tree) CreateMassUpdate(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "massupdate", opts)
 	if err != nil {
 		return err
 	}
@@ -505,58 +407,8 @@ export let each: EntryPoints.MassUpdate.each = (params: EntryPoints.MassUpdate.e
 }
 
 // CreatePortlet creates a portlet script
-func (s *Tree) CreatePortlet(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "portlet", `import {EntryPoints} from "N/types";
-
-/**
- * Portlet script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType Portlet
- */
-
-/** render event handler */
-export let render: EntryPoints.Portlet.render = (params: EntryPoints.Portlet.renderContext) => {
-    // Enter code here
-};
-`, `<portlet scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <portlettype>HTML</portlettype>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-  <scriptdeployments>
-    <scriptdeployment scriptid="{{.DeploymentId}}">
-      <allemployees>T</allemployees>
-      <allpartners>F</allpartners>
-      <allroles>F</allroles>
-      <audslctrole></audslctrole>
-      <dashboardapp>F</dashboardapp>
-      <isdeployed>T</isdeployed>
-      <loglevel>ERROR</loglevel>
-      <runasrole></runasrole>
-      <status>RELEASED</status>
-      <title>{{.ScriptName}}</title>
-    </scriptdeployment>
-  </scriptdeployments>
-</portlet>`, instruct)
+func (s *Tree) CreatePortlet(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "portlet", opts)
 	if err != nil {
 		return err
 	}
@@ -564,80 +416,8 @@ export let render: EntryPoints.Portlet.render = (params: EntryPoints.Portlet.ren
 }
 
 // CreateRestlet creates a restlet script
-func (s *Tree) CreateRestlet(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "restlet", `import {EntryPoints} from "N/types";
-
-/** RESTlet standard return */
-type RestReturn = string | object;
-
-/**
- * RESTlet script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType Restlet
- */
-
-/** GET event handler */
-const get: EntryPoints.RESTlet.get = (requestParams: object): RestReturn => {
-    // Enter code here
-};
-
-/** POST event handler */
-const post: EntryPoints.RESTlet.post = (requestBody: object): RestReturn => {
-    // Enter code here
-};
-
-/** PUT event handler */
-const put: EntryPoints.RESTlet.put = (requestBody: object): RestReturn => {
-    // Enter code here
-};
-
-/** DELETE event handler */
-const remove: EntryPoints.RESTlet.delete_ = (requestParams: object): RestReturn => {
-    // Enter code here
-};
-
-export = {
-    ["get"]: get,
-    ["post"]: post,
-    ["put"]: put,
-    ["delete"]: remove,
-};
-`, `<restlet scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-  <scriptdeployments>
-    <scriptdeployment scriptid="{{.DeploymentId}}">
-      <allemployees>T</allemployees>
-      <allpartners>F</allpartners>
-      <allroles>F</allroles>
-      <audslctrole></audslctrole>
-      <isdeployed>T</isdeployed>
-      <loglevel>ERROR</loglevel>
-      <status>RELEASED</status>
-      <title>{{.ScriptName}}</title>
-    </scriptdeployment>
-  </scriptdeployments>
-</restlet>`, instruct)
+func (s *Tree) CreateRestlet(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "restlet", opts)
 	if err != nil {
 		return err
 	}
@@ -645,57 +425,8 @@ export = {
 }
 
 // CreateScheduled creates a scheduled script
-func (s *Tree) CreateScheduled(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "scheduled", `import {EntryPoints} from "N/types";
-
-/**
- * Scheduled script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType ScheduledScript
- */
-
-/** execute event handler */
-export let execute: EntryPoints.Scheduled.execute = (context: EntryPoints.Scheduled.executeContext) => {
-    // Enter code here
-};
-`, `<scheduledscript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-  <scriptdeployments>
-    <scriptdeployment scriptid="{{.DeploymentId}}">
-      <isdeployed>T</isdeployed>
-      <loglevel>DEBUG</loglevel>
-      <status>NOTSCHEDULED</status>
-      <title>{{.ScriptName}}</title>
-      <recurrence>
-        <single>
-          <repeat></repeat>
-          <startdate>2020-01-01</startdate>
-          <starttime>23:00:00Z</starttime>
-        </single>
-      </recurrence>
-    </scriptdeployment>
-  </scriptdeployments>
-</scheduledscript>`, instruct)
+func (s *Tree) CreateScheduled(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "scheduled", opts)
 	if err != nil {
 		return err
 	}
@@ -703,58 +434,8 @@ export let execute: EntryPoints.Scheduled.execute = (context: EntryPoints.Schedu
 }
 
 // CreateSuitelet creates a suitelet script
-func (s *Tree) CreateSuitelet(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "suitelet", `import {EntryPoints} from "N/types";
-
-/**
- * Suitelet script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType Suitelet
- */
-
-/** onRequest event handler */
-export let onRequest: EntryPoints.Suitelet.onRequest = (context: EntryPoints.Suitelet.onRequestContext) => {
-    // Enter code here
-};
-`, `<suitelet scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-  <scriptdeployments>
-    <scriptdeployment scriptid="{{.DeploymentId}}">
-      <allemployees>T</allemployees>
-      <allpartners>F</allpartners>
-      <allroles>F</allroles>
-      <audslctrole></audslctrole>
-      <eventtype></eventtype>
-      <isdeployed>T</isdeployed>
-      <isonline>F</isonline>
-      <loglevel>ERROR</loglevel>
-      <runasrole>ADMINISTRATOR</runasrole>
-      <status>RELEASED</status>
-      <title>{{.ScriptName}}</title>
-    </scriptdeployment>
-  </scriptdeployments>
-</suitelet>`, instruct)
+func (s *Tree) CreateSuitelet(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "suitelet", opts)
 	if err != nil {
 		return err
 	}
@@ -762,53 +443,8 @@ export let onRequest: EntryPoints.Suitelet.onRequest = (context: EntryPoints.Sui
 }
 
 // CreateUserEvent creates a user event script
-func (s *Tree) CreateUserEvent(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "userevent", `import {EntryPoints} from "N/types";
-
-/**
- * User Event script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType UserEventScript
- */
-
-/** beforeLoad event handler */
-export let beforeLoad: EntryPoints.UserEvent.beforeLoad = (context: EntryPoints.UserEvent.beforeLoadContext) => {
-    // Enter code here
-};
-
-/** beforeSubmit event handler */
-export let beforeSubmit: EntryPoints.UserEvent.beforeSubmit = (context: EntryPoints.UserEvent.beforeSubmitContext) => {
-    // Enter code here
-};
-
-/** afterSubmit event handler */
-export let afterSubmit: EntryPoints.UserEvent.afterSubmit = (context: EntryPoints.UserEvent.afterSubmitContext) => {
-    // Enter code here
-};
-`, `<usereventscript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-</usereventscript>`, instruct)
+func (s *Tree) CreateUserEvent(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "userevent", opts)
 	if err != nil {
 		return err
 	}
@@ -816,45 +452,8 @@ export let afterSubmit: EntryPoints.UserEvent.afterSubmit = (context: EntryPoint
 }
 
 // CreateWorkflowAction creates a workflow action script
-func (s *Tree) CreateWorkflowAction(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "workflowaction", `import {EntryPoints} from "N/types";
-
-/**
- * Workflow script file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NScriptName {{.ScriptName}}
- * @NScriptId {{.ScriptId}}
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- * @NScriptType WorkflowActionScript
- */
-
-/** onAction event handler */
-export let onAction: EntryPoints.WorkflowAction.onAction = (context: EntryPoints.WorkflowAction.onActionContext) => {
-    // Enter code here
-};
-`, `<workflowactionscript scriptid="{{.ScriptId}}">
-  <description>{{.Description}}</description>
-  <isinactive>F</isinactive>
-  <name>{{.ScriptName}}</name>
-  <notifyadmins>F</notifyadmins>
-  <notifyemails></notifyemails>
-  <notifyowner>T</notifyowner>
-  <notifyuser>F</notifyuser>
-  <returnrecordtype>-4</returnrecordtype>
-  <returntype>SELECT</returntype>
-  <scriptfile>[{{.ScriptPath}}]</scriptfile>
-</workflowactionscript>`, instruct)
+func (s *Tree) CreateWorkflowAction(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "workflowaction", opts)
 	if err != nil {
 		return err
 	}
@@ -862,25 +461,8 @@ export let onAction: EntryPoints.WorkflowAction.onAction = (context: EntryPoints
 }
 
 // CreateModule creates a module file
-func (s *Tree) CreateModule(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "type", `/**
- * Type declaration file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- */
-
-`, ``, instruct)
+func (s *Tree) CreateModule(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "module", opts)
 	if err != nil {
 		return err
 	}
@@ -888,26 +470,8 @@ func (s *Tree) CreateModule(global *store.GlobalStore, project *store.ProjectSto
 }
 
 // CreateType creates a type file
-func (s *Tree) CreateType(global *store.GlobalStore, project *store.ProjectStore, instruct string) error {
-	err := s.addDeploymentFiles(global, project, "module", `/**
- * Module file
- *
- * WARNING:
- * TypeScript generated file, do not edit directly
- * source files are located in the repository
- *
- * @project: {{.Project}}
- * @description: {{.Description}}
- *
- * @copyright {{.Date}} {{.CompanyName}}
- * @author {{.UserName}} {{.UserEmail}}
- *
- * @NApiVersion 2.x
- * @NModuleScope SameAccount
- */
-
-export {};
-`, ``, instruct)
+func (s *Tree) CreateType(global *store.GlobalStore, project *store.ProjectStore, opts *ScriptOptions) error {
+	err := s.addDeploymentFiles(global, project, "type", opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,224 @@
+package file
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Directory name for user template overrides
+const templateDirName = ".nsc-templates"
+
+// Built-in templates, one .ts and optional .xml file per script type
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// TemplateInfo describes where a template resolves to
+type TemplateInfo struct {
+	// Template file name
+	Name string
+	// Source of the template: project, user or builtin
+	Source string
+	// Path of the template file
+	Path string
+}
+
+// ScriptTypes returns every script type that can be scaffolded
+func ScriptTypes() []string {
+	return []string{
+		"bundle",
+		"client",
+		"formclient",
+		"mapreduce",
+		"massupdate",
+		"portlet",
+		"restlet",
+		"scheduled",
+		"suitelet",
+		"userevent",
+		"workflowaction",
+		"module",
+		"type",
+	}
+}
+
+// entryPoints lists the entry points each script type template can render
+var entryPoints = map[string][]string{
+	"bundle":         {"afterInstall", "afterUpdate", "beforeInstall", "beforeUninstall", "beforeUpdate"},
+	"client":         {"pageInit", "validateField", "fieldChanged", "postSourcing", "lineInit", "validateLine", "validateInsert", "validateDelete", "sublistChanged", "saveRecord"},
+	"formclient":     {"pageInit", "validateField", "fieldChanged", "postSourcing", "lineInit", "validateLine", "validateInsert", "validateDelete", "sublistChanged", "saveRecord"},
+	"mapreduce":      {"getInputData", "map", "reduce", "summarize"},
+	"massupdate":     {"each"},
+	"portlet":        {"render"},
+	"restlet":        {"get", "post", "put", "delete"},
+	"scheduled":      {"execute"},
+	"suitelet":       {"onRequest"},
+	"userevent":      {"beforeLoad", "beforeSubmit", "afterSubmit"},
+	"workflowaction": {"onAction"},
+}
+
+// requiredEntryPoints lists the entry points a script type cannot run without
+var requiredEntryPoints = map[string][]string{
+	"mapreduce": {"getInputData"},
+}
+
+// EntryPoints returns the entry points of a script type, or nil when it has none
+func EntryPoints(scriptType string) []string {
+	return entryPoints[scriptType]
+}
+
+// selectEntryPoints returns the entry points to render for a script type,
+// from the options or the user. Every entry point is rendered when there is
+// no selection.
+func selectEntryPoints(scriptType string, opts *ScriptOptions) ([]string, error) {
+	valid := entryPoints[scriptType]
+	selected := opts.Entries
+	if len(valid) == 0 {
+		if len(selected) > 0 {
+			return nil, fmt.Errorf("%s files have no entry points to select", scriptType)
+		}
+		return nil, nil
+	}
+	// Ask for the selection when none was given
+	if len(selected) == 0 {
+		if opts.Yes || !util.IsInteractive() || len(valid) == 1 {
+			return valid, nil
+		}
+		return util.MultiSelect(fmt.Sprintf("Select the %s entry points to generate:", scriptType), valid)
+	}
+	// Reject unknown entry points
+	for _, name := range selected {
+		if !slices.Contains(valid, name) {
+			return nil, fmt.Errorf("unknown %s entry point %q, expected one of %s", scriptType, name, strings.Join(valid, ", "))
+		}
+	}
+	for _, name := range requiredEntryPoints[scriptType] {
+		if !slices.Contains(selected, name) {
+			return nil, fmt.Errorf("%s scripts need the %s entry point", scriptType, name)
+		}
+	}
+	return selected, nil
+}
+
+// lookupTemplate returns the content and source of a template, checking the
+// project and user template directories before the built-in templates. An
+// empty content is returned when no template exists for the name.
+func (s *Tree) lookupTemplate(name string) (string, string, error) {
+	// Check the override directories in order
+	dirs, err := s.templateDirs()
+	if err != nil {
+		return "", "", err
+	}
+	for _, dir := range dirs {
+		content, err := os.ReadFile(filepath.Join(dir.path, name))
+		if err == nil {
+			return string(content), dir.source, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", err
+		}
+	}
+
+	// Fall back to the built-in template
+	content, err := builtinTemplates.ReadFile("templates/" + name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return string(content), "builtin", nil
+}
+
+// ListTemplates returns where each built-in template resolves to
+func (s *Tree) ListTemplates() ([]TemplateInfo, error) {
+	var infos []TemplateInfo
+	dirs, err := s.templateDirs()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		_, source, err := s.lookupTemplate(name)
+		if err != nil {
+			return nil, err
+		}
+		// Record the resolved path
+		path := "templates/" + name
+		for _, dir := range dirs {
+			if dir.source == source {
+				path = filepath.Join(dir.path, name)
+			}
+		}
+		infos = append(infos, TemplateInfo{Name: name, Source: source, Path: path})
+	}
+	return infos, nil
+}
+
+// ExportTemplates writes the built-in templates to a directory, defaulting to
+// the project template directory. Existing files are kept unless force is set.
+func (s *Tree) ExportTemplates(dir string, force bool) error {
+	// Default to the project template directory
+	if dir == "" {
+		dir = filepath.Join(s.dirname, templateDirName)
+	}
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	// Copy every built-in template
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		destination := filepath.Join(dir, entry.Name())
+		if !force {
+			if _, err := os.Stat(destination); err == nil {
+				fmt.Printf("skipped %s, already exists\n", destination)
+				continue
+			}
+		}
+		content, err := builtinTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		err = s.createFile(destination, string(content))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("exported %s\n", destination)
+	}
+	return nil
+}
+
+// templateDir represents a template override directory
+type templateDir struct {
+	path   string
+	source string
+}
+
+// templateDirs returns the template override directories in lookup order
+func (s *Tree) templateDirs() ([]templateDir, error) {
+	// Get the user's home directory
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return []templateDir{
+		{filepath.Join(s.dirname, templateDirName), "project"},
+		{filepath.Join(home, templateDirName), "user"},
+	}, nil
+}
@@ -0,0 +1,71 @@
+import {EntryPoints} from "N/types";
+{{- if .Entry "afterInstall"}}
+import onAfterInstallContext = EntryPoints.BundleInstallation.onAfterInstallContext;
+{{- end}}
+{{- if .Entry "afterUpdate"}}
+import onAfterUpdateContext = EntryPoints.BundleInstallation.onAfterUpdateContext;
+{{- end}}
+{{- if .Entry "beforeInstall"}}
+import onBeforeInstallContext = EntryPoints.BundleInstallation.onBeforeInstallContext;
+{{- end}}
+{{- if .Entry "beforeUpdate"}}
+import onBeforeUpdateContext = EntryPoints.BundleInstallation.onBeforeUpdateContext;
+{{- end}}
+{{- if .Entry "beforeUninstall"}}
+import onBeforeUninstallContext = EntryPoints.BundleInstallation.onBeforeUninstallContext;
+{{- end}}
+
+/**
+ * Bundle Installation script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType BundleInstallationScript
+ */
+{{- if .Entry "afterInstall"}}
+
+/** afterInstall event handler */
+export {{.Declare}} afterInstall: EntryPoints.BundleInstallation.afterInstall = (context: onAfterInstallContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "afterUpdate"}}
+
+/** afterUpdate event handler */
+export {{.Declare}} afterUpdate: EntryPoints.BundleInstallation.afterUpdate = (context: onAfterUpdateContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "beforeInstall"}}
+
+/** beforeInstall event handler */
+export {{.Declare}} beforeInstall: EntryPoints.BundleInstallation.beforeInstall = (context: onBeforeInstallContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "beforeUninstall"}}
+
+/** beforeUninstall event handler */
+export {{.Declare}} beforeUninstall: EntryPoints.BundleInstallation.beforeUninstall = (context: onBeforeUninstallContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "beforeUpdate"}}
+
+/** beforeUpdate event handler */
+export {{.Declare}} beforeUpdate: EntryPoints.BundleInstallation.beforeUpdate = (context: onBeforeUpdateContext) => {
+    // Enter code here
+};
+{{- end}}
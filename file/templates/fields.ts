@@ -0,0 +1,16 @@
+/**
+ * Custom field ids file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: Script ids of the custom fields in this project
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ */
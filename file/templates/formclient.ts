@@ -0,0 +1,89 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Form client script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType ClientScript
+ */
+{{- if .Entry "pageInit"}}
+
+/** pageInit event handler */
+export {{.Declare}} pageInit: EntryPoints.Client.pageInit = (context: EntryPoints.Client.pageInitContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "validateField"}}
+
+/** validateField event handler */
+export {{.Declare}} validateField: EntryPoints.Client.validateField = (context: EntryPoints.Client.validateFieldContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "fieldChanged"}}
+
+/** fieldChanged event handler */
+export {{.Declare}} fieldChanged: EntryPoints.Client.fieldChanged = (context: EntryPoints.Client.fieldChangedContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "postSourcing"}}
+
+/** postSourcing event handler */
+export {{.Declare}} postSourcing: EntryPoints.Client.postSourcing = (context: EntryPoints.Client.postSourcingContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "lineInit"}}
+
+/** lineInit event handler */
+export {{.Declare}} lineInit: EntryPoints.Client.lineInit = (context: EntryPoints.Client.lineInitContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "validateLine"}}
+
+/** validateLine event handler */
+export {{.Declare}} validateLine: EntryPoints.Client.validateLine = (context: EntryPoints.Client.validateLineContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "validateInsert"}}
+
+/** validateInsert event handler */
+export {{.Declare}} validateInsert: EntryPoints.Client.validateInsert = (context: EntryPoints.Client.validateInsertContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "validateDelete"}}
+
+/** validateDelete event handler */
+export {{.Declare}} validateDelete: EntryPoints.Client.validateDelete = (context: EntryPoints.Client.validateDeleteContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "sublistChanged"}}
+
+/** sublistChanged event handler */
+export {{.Declare}} sublistChanged: EntryPoints.Client.sublistChanged = (context: EntryPoints.Client.sublistChangedContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "saveRecord"}}
+
+/** saveRecord event handler */
+export {{.Declare}} saveRecord: EntryPoints.Client.saveRecord = (context: EntryPoints.Client.saveRecordContext) => {
+    // Enter code here
+};
+{{- end}}
@@ -0,0 +1,49 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Map/Reduce script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType MapReduceScript
+ */
+{{- if .Entry "getInputData"}}
+
+/** getInputData event handler */
+export {{.Declare}} getInputData: EntryPoints.MapReduce.getInputData = (context: EntryPoints.MapReduce.getInputDataContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "map"}}
+
+/** map event handler */
+export {{.Declare}} map: EntryPoints.MapReduce.map = (context: EntryPoints.MapReduce.mapContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "reduce"}}
+
+/** reduce event handler */
+export {{.Declare}} reduce: EntryPoints.MapReduce.reduce = (context: EntryPoints.MapReduce.reduceContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "summarize"}}
+
+/** summarize event handler */
+export {{.Declare}} summarize: EntryPoints.MapReduce.summarize = (summary: EntryPoints.MapReduce.summarizeContext) => {
+    // Enter code here
+};
+{{- end}}
@@ -0,0 +1,28 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Mass Update script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType MassUpdateScript
+ */
+{{- if .Entry "each"}}
+
+/** each event handler */
+export {{.Declare}} each: EntryPoints.MassUpdate.each = (params: EntryPoints.MassUpdate.eachContext) => {
+    // Enter code here
+};
+{{- end}}
@@ -0,0 +1,18 @@
+/**
+ * Module file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ */
+
+export {};
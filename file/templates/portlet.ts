@@ -0,0 +1,28 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Portlet script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType Portlet
+ */
+{{- if .Entry "render"}}
+
+/** render event handler */
+export {{.Declare}} render: EntryPoints.Portlet.render = (params: EntryPoints.Portlet.renderContext) => {
+    // Enter code here
+};
+{{- end}}
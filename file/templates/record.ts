@@ -0,0 +1,69 @@
+import * as record from "N/record";
+
+/**
+ * Custom record accessor file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ */
+
+/** {{.RecordName}} record type id */
+export const RECORD_TYPE = "{{.ScriptId}}";
+
+/** {{.RecordName}} field ids */
+export const Fields = {
+{{- range .Fields}}
+    {{.Key}}: "{{.ScriptId}}",
+{{- end}}
+};
+
+/** Typed accessor for the {{.RecordName}} record */
+export class {{.ClassName}} {
+    constructor(public readonly rec: record.Record) {
+    }
+
+    /** Create a new {{.RecordName}} record */
+    static create(isDynamic: boolean = false): {{.ClassName}} {
+        return new {{.ClassName}}(record.create({type: RECORD_TYPE, isDynamic}));
+    }
+
+    /** Load an existing {{.RecordName}} record */
+    static load(id: number, isDynamic: boolean = false): {{.ClassName}} {
+        return new {{.ClassName}}(record.load({type: RECORD_TYPE, id, isDynamic}));
+    }
+
+    /** Record name */
+    get name(): string {
+        return this.rec.getValue({fieldId: "name"}) as string;
+    }
+
+    set name(value: string) {
+        this.rec.setValue({fieldId: "name", value});
+    }
+{{- range .Fields}}
+
+    /** {{.Label | doc}} */
+    get {{.Key}}(): {{.TSType}} {
+        return this.rec.getValue({fieldId: Fields.{{.Key}}}) as {{.TSType}};
+    }
+
+    set {{.Key}}(value: {{.TSType}}) {
+        this.rec.setValue({fieldId: Fields.{{.Key}}, value});
+    }
+{{- end}}
+
+    /** Save the record and return its internal id */
+    save(): number {
+        return this.rec.save();
+    }
+}
@@ -0,0 +1,67 @@
+import {EntryPoints} from "N/types";
+
+/** RESTlet standard return */
+type RestReturn = string | object;
+
+/**
+ * RESTlet script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType Restlet
+ */
+{{- if .Entry "get"}}
+
+/** GET event handler */
+const get: EntryPoints.RESTlet.get = (requestParams: object): RestReturn => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "post"}}
+
+/** POST event handler */
+const post: EntryPoints.RESTlet.post = (requestBody: object): RestReturn => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "put"}}
+
+/** PUT event handler */
+const put: EntryPoints.RESTlet.put = (requestBody: object): RestReturn => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "delete"}}
+
+/** DELETE event handler */
+const remove: EntryPoints.RESTlet.delete_ = (requestParams: object): RestReturn => {
+    // Enter code here
+};
+{{- end}}
+
+export = {
+{{- if .Entry "get"}}
+    {{if .Modern}}get{{else}}["get"]: get{{end}},
+{{- end}}
+{{- if .Entry "post"}}
+    {{if .Modern}}post{{else}}["post"]: post{{end}},
+{{- end}}
+{{- if .Entry "put"}}
+    {{if .Modern}}put{{else}}["put"]: put{{end}},
+{{- end}}
+{{- if .Entry "delete"}}
+    {{if .Modern}}delete: remove{{else}}["delete"]: remove{{end}},
+{{- end}}
+};
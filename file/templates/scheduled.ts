@@ -0,0 +1,28 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Scheduled script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType ScheduledScript
+ */
+{{- if .Entry "execute"}}
+
+/** execute event handler */
+export {{.Declare}} execute: EntryPoints.Scheduled.execute = (context: EntryPoints.Scheduled.executeContext) => {
+    // Enter code here
+};
+{{- end}}
@@ -0,0 +1,28 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Suitelet script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType Suitelet
+ */
+{{- if .Entry "onRequest"}}
+
+/** onRequest event handler */
+export {{.Declare}} onRequest: EntryPoints.Suitelet.onRequest = (context: EntryPoints.Suitelet.onRequestContext) => {
+    // Enter code here
+};
+{{- end}}
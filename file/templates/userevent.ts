@@ -0,0 +1,42 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * User Event script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType UserEventScript
+ */
+{{- if .Entry "beforeLoad"}}
+
+/** beforeLoad event handler */
+export {{.Declare}} beforeLoad: EntryPoints.UserEvent.beforeLoad = (context: EntryPoints.UserEvent.beforeLoadContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "beforeSubmit"}}
+
+/** beforeSubmit event handler */
+export {{.Declare}} beforeSubmit: EntryPoints.UserEvent.beforeSubmit = (context: EntryPoints.UserEvent.beforeSubmitContext) => {
+    // Enter code here
+};
+{{- end}}
+{{- if .Entry "afterSubmit"}}
+
+/** afterSubmit event handler */
+export {{.Declare}} afterSubmit: EntryPoints.UserEvent.afterSubmit = (context: EntryPoints.UserEvent.afterSubmitContext) => {
+    // Enter code here
+};
+{{- end}}
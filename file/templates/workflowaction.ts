@@ -0,0 +1,28 @@
+import {EntryPoints} from "N/types";
+
+/**
+ * Workflow script file
+ *
+ * WARNING:
+ * TypeScript generated file, do not edit directly
+ * source files are located in the repository
+ *
+ * @project: {{.Project}}
+ * @description: {{.Description}}
+ *
+ * @copyright {{.Date}} {{.CompanyName}}
+ * @author {{.UserName}} {{.UserEmail}}
+ *
+ * @NScriptName {{.ScriptName}}
+ * @NScriptId {{.ScriptId}}
+ * @NApiVersion {{.ApiVersion}}
+ * @NModuleScope SameAccount
+ * @NScriptType WorkflowActionScript
+ */
+{{- if .Entry "onAction"}}
+
+/** onAction event handler */
+export {{.Declare}} onAction: EntryPoints.WorkflowAction.onAction = (context: EntryPoints.WorkflowAction.onActionContext) => {
+    // Enter code here
+};
+{{- end}}
@@ -0,0 +1,165 @@
+package file
+
+import (
+	"fmt"
+	"netsuite-companion/store"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// tsOptions holds the compiler options that depend on the SuiteScript API version
+type tsOptions struct {
+	// ECMAScript target
+	Target string
+	// Module system
+	Module string
+	// Library declarations
+	Lib []string
+}
+
+// Patterns of the compiler options rewritten when the API version changes
+var (
+	tsTargetPattern  = regexp.MustCompile(`"target"\s*:\s*"[^"]*"`)
+	tsModulePattern  = regexp.MustCompile(`"module"\s*:\s*"[^"]*"`)
+	tsLibPattern     = regexp.MustCompile(`(?s)"lib"\s*:\s*\[[^\]]*\]`)
+	tsIncludePattern = regexp.MustCompile(`(?s)"include"\s*:\s*\[[^\]]*\]`)
+)
+
+// tsProjectsExclude keeps the project folders, which carry their own tsconfig.json, out of the root one
+const tsProjectsExclude = `"exclude": [
+    "src/FileCabinet/SuiteScripts/*/*"
+  ]`
+
+// tsOptionsFor returns the compiler options for an API version. SuiteScript
+// 2.1 runs ES2019 code, older versions need ES5. Every version loads scripts
+// as AMD modules, so there is no ES module output.
+func tsOptionsFor(apiVersion string) tsOptions {
+	if apiVersion == "2.1" {
+		return tsOptions{Target: "es2019", Module: "amd", Lib: []string{"es2019", "dom"}}
+	}
+	return tsOptions{Target: "es5", Module: "umd", Lib: []string{"es5", "es2015.promise", "dom"}}
+}
+
+// libJSON formats the library list as a JSON array indented for tsconfig.json
+func (o tsOptions) libJSON() string {
+	return "[\n      \"" + strings.Join(o.Lib, "\",\n      \"") + "\"\n    ]"
+}
+
+// tsConfig returns the tsconfig.json content for an API version
+func tsConfig(apiVersion string) string {
+	o := tsOptionsFor(apiVersion)
+	return fmt.Sprintf(`{
+  "compilerOptions": {
+    "target": "%s",
+    "module": "%s",
+    "moduleResolution": "node",
+    "sourceMap": false,
+    "newLine": "LF",
+    "experimentalDecorators": true,
+    "noImplicitAny": true,
+    "noImplicitThis": true,
+    "strictNullChecks": true,
+    "strictFunctionTypes": true,
+    "strictPropertyInitialization": true,
+    "baseUrl": "./",
+    "noUnusedLocals": true,
+    "noUnusedParameters": true,
+    "noImplicitReturns": true,
+    "noFallthroughCasesInSwitch": true,
+    "lib": %s,
+    "paths": {
+      "N": [
+        "node_modules/@hitc/netsuite-types/N"
+      ],
+      "N/*": [
+        "node_modules/@hitc/netsuite-types/N/*"
+      ]
+    }
+  },
+  "include": [
+    "src"
+  ],
+  %s
+}`, o.Target, o.Module, o.libJSON(), tsProjectsExclude)
+}
+
+// projectTsConfig returns the tsconfig.json of a project folder, extending the
+// root one with the compiler options of the project API version
+func projectTsConfig(apiVersion string, extends string) string {
+	o := tsOptionsFor(apiVersion)
+	return fmt.Sprintf(`{
+  "extends": "%s",
+  "compilerOptions": {
+    "target": "%s",
+    "module": "%s",
+    "lib": %s
+  },
+  "include": [
+    "./**/*.ts"
+  ]
+}`, extends, o.Target, o.Module, o.libJSON())
+}
+
+// UpdateTsConfig writes the tsconfig.json of the current project folder for
+// its API version, so projects of different versions compile side by side,
+// and keeps the project folders out of the root tsconfig.json
+func (s *Tree) UpdateTsConfig(global *store.GlobalStore, project *store.ProjectStore) error {
+	rootPath := filepath.Join(s.dirname, "tsconfig.json")
+	rootContent, err := os.ReadFile(rootPath)
+	if os.IsNotExist(err) {
+		// Nothing to update before init
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	folder, err := projectPath(global, project)
+	if err != nil {
+		return err
+	}
+	projectDir := folder.Local(s.dirname)
+	path := filepath.Join(projectDir, "tsconfig.json")
+
+	// Retarget an existing project file, keeping the rest of it as it is
+	apiVersion := project.ApiVersion()
+	o := tsOptionsFor(apiVersion)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated := string(content)
+	if err == nil {
+		updated = tsTargetPattern.ReplaceAllString(updated, fmt.Sprintf(`"target": "%s"`, o.Target))
+		updated = tsModulePattern.ReplaceAllString(updated, fmt.Sprintf(`"module": "%s"`, o.Module))
+		updated = tsLibPattern.ReplaceAllLiteralString(updated, `"lib": `+o.libJSON())
+	} else {
+		extends, err := filepath.Rel(projectDir, rootPath)
+		if err != nil {
+			return err
+		}
+		updated = projectTsConfig(apiVersion, filepath.ToSlash(extends))
+	}
+
+	// Exclude the project folders from root files written before they had their own
+	rootUpdated := string(rootContent)
+	if !strings.Contains(rootUpdated, `"exclude"`) {
+		rootUpdated = tsIncludePattern.ReplaceAllLiteralString(rootUpdated, tsIncludePattern.FindString(rootUpdated)+",\n  "+tsProjectsExclude)
+	}
+	if updated == string(content) && rootUpdated == string(rootContent) {
+		return nil
+	}
+	err = s.atomically(func() error {
+		err := s.createFile(path, updated)
+		if err != nil {
+			return err
+		}
+		return s.createFile(rootPath, rootUpdated)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s for SuiteScript %s\n", relPath(s.dirname, path), apiVersion)
+	return nil
+}
@@ -0,0 +1,258 @@
+package file
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// transaction holds the files of one operation in a temporary folder until
+// they are committed together
+type transaction struct {
+	// Temporary folder, inside the project root so files can be renamed into place
+	tmp string
+	// Folders to create on commit
+	dirs []string
+	// Files to move into place on commit
+	writes []txnWrite
+}
+
+// txnWrite is a file held by a transaction
+type txnWrite struct {
+	// Final path of the file
+	destination string
+	// Path of the file in the temporary folder, empty when the file is removed
+	staged string
+}
+
+// txnMark is the state of a transaction at one point of an operation
+type txnMark struct {
+	dirs   []string
+	writes []txnWrite
+}
+
+// txnUndo records how to reverse one committed change
+type txnUndo struct {
+	// Path created or replaced by the change
+	path string
+	// Where the replaced file was moved, empty when the path was created
+	original string
+}
+
+// begin starts a transaction for the files written by an operation. Nil is
+// returned when one is already running, so nested operations join the outer
+// one.
+func (s *Tree) begin() (*transaction, error) {
+	if s.txn != nil {
+		return nil, nil
+	}
+	err := os.MkdirAll(s.dirname, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp(s.dirname, ".nsc-txn-")
+	if err != nil {
+		return nil, err
+	}
+	s.txn = &transaction{tmp: tmp}
+	return s.txn, nil
+}
+
+// finish commits the transaction when the operation succeeded, and rolls it
+// back otherwise. It is meant to be deferred with the operation error.
+func (s *Tree) finish(txn *transaction, err *error) {
+	if txn == nil {
+		return
+	}
+	s.txn = nil
+	defer os.RemoveAll(txn.tmp)
+	if *err != nil {
+		return
+	}
+	*err = txn.commit()
+}
+
+// atomically runs an operation in a transaction, so the files it writes are
+// committed together or not at all
+func (s *Tree) atomically(operation func() error) (err error) {
+	txn, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer s.finish(txn, &err)
+	return operation()
+}
+
+// mkdir creates a folder, or holds it back until the transaction commits
+func (s *Tree) mkdir(path string) error {
+	if s.txn != nil {
+		s.txn.dirs = append(s.txn.dirs, path)
+		return nil
+	}
+	return os.MkdirAll(path, os.ModePerm)
+}
+
+// write validates a file and holds it in the temporary folder
+func (t *transaction) write(destination string, content string) error {
+	err := validateContent(destination, content)
+	if err != nil {
+		return err
+	}
+	// A replaced file keeps its permissions, new ones are not writable by others
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(destination); err == nil {
+		perm = info.Mode().Perm()
+	}
+	staged := filepath.Join(t.tmp, fmt.Sprintf("%d_%s", len(t.writes), filepath.Base(destination)))
+	err = os.WriteFile(staged, []byte(content), perm)
+	if err != nil {
+		return err
+	}
+	t.hold(destination, staged)
+	return nil
+}
+
+// hold records the staged file of a destination, empty to remove it. A later
+// change to the same path replaces the earlier one.
+func (t *transaction) hold(destination string, staged string) {
+	for i, w := range t.writes {
+		if w.destination == destination {
+			t.writes[i].staged = staged
+			return
+		}
+	}
+	t.writes = append(t.writes, txnWrite{destination: destination, staged: staged})
+}
+
+// mark returns the current state of the transaction
+func (t *transaction) mark() txnMark {
+	return txnMark{dirs: slices.Clone(t.dirs), writes: slices.Clone(t.writes)}
+}
+
+// rewind drops every change held since a mark
+func (t *transaction) rewind(mark txnMark) {
+	t.dirs, t.writes = mark.dirs, mark.writes
+}
+
+// since returns the writes added or replaced since a mark
+func (t *transaction) since(mark txnMark) []txnWrite {
+	var changed []txnWrite
+	for i, w := range t.writes {
+		if i >= len(mark.writes) || mark.writes[i] != w {
+			changed = append(changed, w)
+		}
+	}
+	return changed
+}
+
+// removeFile removes a file, or holds the removal back until the transaction commits
+func (s *Tree) removeFile(path string) error {
+	if s.txn != nil {
+		s.txn.hold(path, "")
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// validateContent checks XML and JSON files are well formed
+func validateContent(destination string, content string) error {
+	switch strings.ToLower(filepath.Ext(destination)) {
+	case ".xml":
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			_, err := decoder.Token()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid XML in %s: %w", filepath.Base(destination), err)
+			}
+		}
+	case ".json":
+		if !json.Valid([]byte(content)) {
+			return fmt.Errorf("invalid JSON in %s", filepath.Base(destination))
+		}
+	}
+	return nil
+}
+
+// commit creates the folders and renames every file into place, undoing
+// everything done so far when a step fails
+func (t *transaction) commit() error {
+	var undo []txnUndo
+	err := t.apply(&undo)
+	if err == nil {
+		return nil
+	}
+	// Undo the changes in reverse order
+	for i := len(undo) - 1; i >= 0; i-- {
+		u := undo[i]
+		if u.original != "" {
+			_ = os.Rename(u.original, u.path)
+		} else {
+			_ = os.RemoveAll(u.path)
+		}
+	}
+	return fmt.Errorf("%w, every change was rolled back", err)
+}
+
+// apply makes the changes of the transaction, recording how to undo them
+func (t *transaction) apply(undo *[]txnUndo) error {
+	// Create the folders, remembering the topmost one created
+	dirs := append([]string{}, t.dirs...)
+	for _, w := range t.writes {
+		dirs = append(dirs, filepath.Dir(w.destination))
+	}
+	for _, dir := range dirs {
+		created := topMissing(dir)
+		err := os.MkdirAll(dir, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		if created != "" {
+			*undo = append(*undo, txnUndo{path: created})
+		}
+	}
+	// Move the files into place, keeping the files they replace or remove
+	for i, w := range t.writes {
+		var original string
+		if _, err := os.Stat(w.destination); err == nil {
+			original = filepath.Join(t.tmp, fmt.Sprintf("original_%d", i))
+			err = os.Rename(w.destination, original)
+			if err != nil {
+				return err
+			}
+		}
+		*undo = append(*undo, txnUndo{path: w.destination, original: original})
+		if w.staged == "" {
+			continue
+		}
+		err := os.Rename(w.staged, w.destination)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topMissing returns the topmost missing folder of a path, or an empty string when it exists
+func topMissing(dir string) string {
+	missing := ""
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return missing
+		}
+		missing = dir
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return missing
+		}
+		dir = parent
+	}
+}
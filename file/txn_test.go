@@ -0,0 +1,233 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// txnStep is a change made inside a test transaction
+type txnStep struct {
+	// Path relative to the tree root
+	path string
+	// Content written, ignored for removals
+	content string
+	// Whether the path is removed instead of written
+	remove bool
+}
+
+// readTree returns the content of the given paths, "-" standing for a missing file
+func readTree(t *testing.T, root string, paths []string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(root, path))
+		if os.IsNotExist(err) {
+			files[path] = "-"
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[path] = string(content)
+	}
+	return files
+}
+
+func TestTransactionCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		// Files on disk before the transaction
+		before map[string]string
+		steps  []txnStep
+		// Files expected after the commit, "-" for a missing one
+		after map[string]string
+	}{
+		{
+			name:  "creates files and folders",
+			steps: []txnStep{{path: "a.ts", content: "a"}, {path: "dir/sub/b.ts", content: "b"}},
+			after: map[string]string{"a.ts": "a", "dir/sub/b.ts": "b"},
+		},
+		{
+			name:   "replaces and removes files",
+			before: map[string]string{"a.ts": "old", "b.ts": "old"},
+			steps:  []txnStep{{path: "a.ts", content: "new"}, {path: "b.ts", remove: true}},
+			after:  map[string]string{"a.ts": "new", "b.ts": "-"},
+		},
+		{
+			name:  "keeps the last write to a path",
+			steps: []txnStep{{path: "a.ts", content: "first"}, {path: "a.ts", content: "second"}},
+			after: map[string]string{"a.ts": "second"},
+		},
+		{
+			name:   "drops a write followed by a removal",
+			before: map[string]string{"a.ts": "old"},
+			steps:  []txnStep{{path: "a.ts", content: "new"}, {path: "a.ts", remove: true}, {path: "b.ts", content: "b"}, {path: "b.ts", remove: true}},
+			after:  map[string]string{"a.ts": "-", "b.ts": "-"},
+		},
+		{
+			name:   "writes a file again after its removal",
+			before: map[string]string{"a.ts": "old"},
+			steps:  []txnStep{{path: "a.ts", remove: true}, {path: "a.ts", content: "new"}},
+			after:  map[string]string{"a.ts": "new"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree := CreateTree(t.TempDir())
+			for path, content := range test.before {
+				if err := os.WriteFile(filepath.Join(tree.dirname, path), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := tree.atomically(func() error {
+				for _, step := range test.steps {
+					path := filepath.Join(tree.dirname, step.path)
+					var err error
+					if step.remove {
+						err = tree.removeFile(path)
+					} else {
+						err = tree.createFile(path, step.content)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				// Nothing reaches the disk before the commit
+				for path, content := range readTree(t, tree.dirname, keys(test.after)) {
+					if want, ok := test.before[path]; (ok && content != want) || (!ok && content != "-") {
+						t.Errorf("%s changed before the commit: %q", path, content)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, content := range readTree(t, tree.dirname, keys(test.after)) {
+				if content != test.after[path] {
+					t.Errorf("%s: got %q, want %q", path, content, test.after[path])
+				}
+			}
+			assertNoTxnFolder(t, tree.dirname)
+		})
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	tree := CreateTree(t.TempDir())
+	if err := os.WriteFile(filepath.Join(tree.dirname, "kept.ts"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := tree.atomically(func() error {
+		for _, step := range []txnStep{
+			{path: "new/created.ts", content: "created"},
+			{path: "kept.ts", content: "replaced"},
+			{path: "broken.ts", content: "broken"},
+		} {
+			if err := tree.createFile(filepath.Join(tree.dirname, step.path), step.content); err != nil {
+				return err
+			}
+		}
+		// Make the last rename fail by dropping its staged file
+		return os.Remove(tree.txn.writes[len(tree.txn.writes)-1].staged)
+	})
+	if err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	// Every change made before the failure is undone
+	want := map[string]string{"new/created.ts": "-", "kept.ts": "old", "broken.ts": "-"}
+	for path, content := range readTree(t, tree.dirname, keys(want)) {
+		if content != want[path] {
+			t.Errorf("%s: got %q, want %q", path, content, want[path])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tree.dirname, "new")); !os.IsNotExist(err) {
+		t.Errorf("created folder left behind: %v", err)
+	}
+	assertNoTxnFolder(t, tree.dirname)
+}
+
+func TestTransactionOperationError(t *testing.T) {
+	tree := CreateTree(t.TempDir())
+	err := tree.atomically(func() error {
+		if err := tree.createFile(filepath.Join(tree.dirname, "a.ts"), "a"); err != nil {
+			return err
+		}
+		// Malformed objects are refused before the commit
+		return tree.createFile(filepath.Join(tree.dirname, "a.xml"), "<broken>")
+	})
+	if err == nil {
+		t.Fatal("expected invalid XML to be refused")
+	}
+	if _, err := os.Stat(filepath.Join(tree.dirname, "a.ts")); !os.IsNotExist(err) {
+		t.Errorf("file written by a failed operation: %v", err)
+	}
+	assertNoTxnFolder(t, tree.dirname)
+}
+
+func TestTransactionMarkRewind(t *testing.T) {
+	tree := CreateTree(t.TempDir())
+	path := func(name string) string {
+		return filepath.Join(tree.dirname, name)
+	}
+	err := tree.atomically(func() error {
+		if err := tree.createFile(path("a.ts"), "a"); err != nil {
+			return err
+		}
+		mark := tree.txn.mark()
+		// Changes since the mark are new paths and replaced ones
+		if err := tree.createFile(path("b.ts"), "b"); err != nil {
+			return err
+		}
+		if err := tree.createFile(path("a.ts"), "a2"); err != nil {
+			return err
+		}
+		if err := tree.mkdir(path("dir")); err != nil {
+			return err
+		}
+		changed := tree.txn.since(mark)
+		if len(changed) != 2 || changed[0].destination != path("a.ts") || changed[1].destination != path("b.ts") {
+			t.Errorf("got changes %+v, want a.ts and b.ts", changed)
+		}
+		// Rewinding drops them all
+		tree.txn.rewind(mark)
+		if changed := tree.txn.since(mark); len(changed) != 0 {
+			t.Errorf("got changes %+v after rewind, want none", changed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a.ts": "a", "b.ts": "-"}
+	for name, content := range readTree(t, tree.dirname, keys(want)) {
+		if content != want[name] {
+			t.Errorf("%s: got %q, want %q", name, content, want[name])
+		}
+	}
+	if _, err := os.Stat(path("dir")); !os.IsNotExist(err) {
+		t.Errorf("rewound folder created: %v", err)
+	}
+}
+
+// keys returns the keys of a map
+func keys(m map[string]string) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
+
+// assertNoTxnFolder checks the temporary folder of the transaction was removed
+func assertNoTxnFolder(t *testing.T, root string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(root, ".nsc-txn-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("temporary folders left behind: %v", matches)
+	}
+}
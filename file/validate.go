@@ -0,0 +1,152 @@
+package file
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"netsuite-companion/store"
+	"netsuite-companion/util"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a problem found in a project file
+type Diagnostic struct {
+	// File, relative to the project root
+	Path string
+	// Line of the problem, 0 when it concerns the whole file
+	Line int
+	// Description of the problem
+	Message string
+}
+
+// String formats the diagnostic as file:line: message
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s", d.Path, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+}
+
+// Validate checks the objects of src/Objects against the FileCabinet: script
+// file references must resolve, script headers must agree with their object,
+// ids must carry the vendor prefix and be declared only once
+func (s *Tree) Validate(global *store.GlobalStore) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	report := func(path string, line int, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{Path: relPath(s.dirname, path), Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Parse every object, reporting the ones that are not well formed
+	paths, err := filepath.Glob(filepath.Join(s.dirname, "src", "Objects", "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	var objects []*sdfObject
+	for _, path := range paths {
+		object, err := readObject(path)
+		var syntax *xml.SyntaxError
+		if errors.As(err, &syntax) {
+			report(path, syntax.Line, "invalid XML: %s", syntax.Msg)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	// Check each object
+	declared := map[string]objectId{}
+	declaredIn := map[string]string{}
+	for _, object := range objects {
+		if object.scriptId.id == "" {
+			report(object.path, 1, "%s has no scriptid", object.kind)
+		}
+		for _, id := range object.ids {
+			// Check the vendor prefix
+			if message := checkIdPrefix(global, id.id); message != "" {
+				report(object.path, id.line, "%s", message)
+			}
+			// Check the id is declared once
+			if first, ok := declared[id.id]; ok {
+				report(object.path, id.line, "%s is already declared at %s:%d", id.id, relPath(s.dirname, declaredIn[id.id]), first.line)
+				continue
+			}
+			declared[id.id], declaredIn[id.id] = id, object.path
+		}
+		// Check the script file
+		if object.scriptFile != "" {
+			diagnostics = append(diagnostics, s.checkScriptFile(object)...)
+		}
+	}
+
+	// Sort by file and line
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Path != diagnostics[j].Path {
+			return diagnostics[i].Path < diagnostics[j].Path
+		}
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+	return diagnostics, nil
+}
+
+// checkIdPrefix returns a message when an id does not carry the vendor prefix
+// after its object prefix, as in customscript_acm_name
+func checkIdPrefix(global *store.GlobalStore, id string) string {
+	vendor := strings.ToLower(global.VendorPrefix)
+	if vendor == "" {
+		return ""
+	}
+	prefix, rest, ok := strings.Cut(id, "_")
+	if !ok {
+		return fmt.Sprintf("%s has no object prefix", id)
+	}
+	if !strings.HasPrefix(strings.ToLower(rest), vendor+"_") {
+		return fmt.Sprintf("%s does not carry the vendor prefix, expected %s_%s_...", id, prefix, vendor)
+	}
+	return ""
+}
+
+// checkScriptFile checks the script file of an object exists, as the
+// referenced file or the TypeScript file it is compiled from, and that the
+// script header declares the object id
+func (s *Tree) checkScriptFile(object *sdfObject) []Diagnostic {
+	var diagnostics []Diagnostic
+	report := func(path string, line int, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{Path: relPath(s.dirname, path), Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Check the reference is written as SDF expects it
+	reference := ParseCabinetPath(object.scriptFile)
+	if object.scriptFile != reference.Reference() {
+		report(object.path, object.scriptFileLine, "script file %s should be written %s", object.scriptFile, reference.Reference())
+	}
+	// Resolve the reference against the FileCabinet
+	source := reference.WithExt(".ts").Local(s.dirname)
+	exists, sourceExists := util.Exists(reference.Local(s.dirname)), util.Exists(source)
+	if !exists && !sourceExists {
+		report(object.path, object.scriptFileLine, "script file %s does not exist", object.scriptFile)
+		return diagnostics
+	}
+	if !sourceExists {
+		return diagnostics
+	}
+
+	// Compare the script header with the object
+	tags, err := readHeader(source)
+	if err != nil {
+		report(source, 0, "%s", err)
+		return diagnostics
+	}
+	header, ok := tags["NScriptId"]
+	if !ok {
+		report(source, 1, "script header has no @NScriptId, expected %s", object.scriptId.id)
+		return diagnostics
+	}
+	if header.value != object.scriptId.id {
+		report(source, header.line, "@NScriptId %s does not match scriptid %s of %s", header.value, object.scriptId.id, relPath(s.dirname, object.path))
+	}
+	return diagnostics
+}
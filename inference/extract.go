@@ -0,0 +1,38 @@
+package inference
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fencePattern matches a markdown code fence with an optional language
+var fencePattern = regexp.MustCompile("(?ms)^[ \\t]*```[ \\t]*([\\w+-]*)[ \\t]*\\n(.*?)^[ \\t]*```")
+
+// codeStartPattern matches the first line of a TypeScript file
+var codeStartPattern = regexp.MustCompile(`^\s*(import\b|export\b|/\*|//|const\b|let\b|var\b|type\b|interface\b|declare\b|function\b|class\b)`)
+
+// ExtractCode returns the code of a model answer. The largest fenced code
+// block is used when the answer has fences, otherwise any chatty lines
+// before the code are dropped.
+func ExtractCode(answer string) string {
+	// Use the largest fenced block
+	blocks := fencePattern.FindAllStringSubmatch(answer, -1)
+	if len(blocks) > 0 {
+		code := ""
+		for _, block := range blocks {
+			if len(block[2]) > len(code) {
+				code = block[2]
+			}
+		}
+		return strings.TrimSpace(code) + "\n"
+	}
+
+	// Drop the lines before the first line of code
+	lines := strings.Split(answer, "\n")
+	for i, line := range lines {
+		if codeStartPattern.MatchString(line) {
+			return strings.TrimSpace(strings.Join(lines[i:], "\n")) + "\n"
+		}
+	}
+	return strings.TrimSpace(answer) + "\n"
+}
@@ -0,0 +1,49 @@
+package inference
+
+import "testing"
+
+func TestExtractCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   string
+	}{
+		{
+			name:   "plain code",
+			answer: "export const a = 1;\n",
+			want:   "export const a = 1;\n",
+		},
+		{
+			name:   "fenced code",
+			answer: "Here is the script:\n```typescript\nconst a = 1;\n```\nHope it helps.",
+			want:   "const a = 1;\n",
+		},
+		{
+			name:   "largest fence",
+			answer: "```ts\nconst a = 1;\nexport = {a};\n```\nUsage:\n```\na()\n```\n",
+			want:   "const a = 1;\nexport = {a};\n",
+		},
+		{
+			name:   "indented fence without language",
+			answer: "  ```\n  import * as log from \"N/log\";\n  ```",
+			want:   "import * as log from \"N/log\";\n",
+		},
+		{
+			name:   "chatty preamble",
+			answer: "Sure! Below is the updated file.\n\n/**\n * @NApiVersion 2.1\n */\nexport = {};\n",
+			want:   "/**\n * @NApiVersion 2.1\n */\nexport = {};\n",
+		},
+		{
+			name:   "no code",
+			answer: "  I cannot help with that.  ",
+			want:   "I cannot help with that.\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExtractCode(test.answer); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package inference
+
+import (
+	"context"
+	"fmt"
+	"github.com/sashabaranov/go-openai"
+	"net/http"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderOpenAI     = "openai"     // OpenAI API
+	ProviderAzure      = "azure"      // Azure OpenAI service
+	ProviderCompatible = "compatible" // Any OpenAI compatible API, such as a gateway or local model server
+)
+
+// Provider completes a chat made of a system prompt and a user message
+type Provider interface {
+	// Complete returns the model answer to the prompts
+	Complete(ctx context.Context, system string, user string) (string, error)
+}
+
+// Config holds the settings used to create a provider
+type Config struct {
+	// Provider name
+	Provider string
+	// Model name, or deployment name on Azure
+	Model string
+	// API base URL, required by the azure and compatible providers
+	BaseURL string
+	// API version, used by the azure provider
+	ApiVersion string
+	// API key, optional for the compatible provider
+	ApiKey string
+	// Sampling temperature, zero uses the provider default
+	Temperature float64
+	// Request timeout, zero means no timeout
+	Timeout time.Duration
+}
+
+// Providers returns the names of the supported providers
+func Providers() []string {
+	return []string{ProviderOpenAI, ProviderAzure, ProviderCompatible}
+}
+
+// New creates the provider named in the config
+func New(config Config) (Provider, error) {
+	switch config.Provider {
+	case ProviderOpenAI:
+		return NewOpenAI(config)
+	case ProviderAzure:
+		return NewAzure(config)
+	case ProviderCompatible:
+		return NewCompatible(config)
+	}
+	return nil, fmt.Errorf("unknown inference provider %q, expected one of %v", config.Provider, Providers())
+}
+
+// NewOpenAI creates a provider for the OpenAI API
+func NewOpenAI(config Config) (Provider, error) {
+	if config.ApiKey == "" {
+		return nil, fmt.Errorf("openai_api_key must be set for the %s provider", ProviderOpenAI)
+	}
+	clientConfig := openai.DefaultConfig(config.ApiKey)
+	// Allow a proxy in front of the OpenAI API
+	if config.BaseURL != "" {
+		clientConfig.BaseURL = config.BaseURL
+	}
+	return newChatProvider(ProviderOpenAI, config, clientConfig), nil
+}
+
+// NewAzure creates a provider for an Azure OpenAI resource, the model being the deployment name
+func NewAzure(config Config) (Provider, error) {
+	if config.ApiKey == "" {
+		return nil, fmt.Errorf("openai_api_key must be set for the %s provider", ProviderAzure)
+	}
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("inference_base_url must be set for the %s provider", ProviderAzure)
+	}
+	clientConfig := openai.DefaultAzureConfig(config.ApiKey, config.BaseURL)
+	if config.ApiVersion != "" {
+		clientConfig.APIVersion = config.ApiVersion
+	}
+	// Use the model name as the deployment name unchanged
+	clientConfig.AzureModelMapperFunc = func(model string) string {
+		return model
+	}
+	return newChatProvider(ProviderAzure, config, clientConfig), nil
+}
+
+// NewCompatible creates a provider for an OpenAI compatible API at the base URL
+func NewCompatible(config Config) (Provider, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("inference_base_url must be set for the %s provider", ProviderCompatible)
+	}
+	clientConfig := openai.DefaultConfig(config.ApiKey)
+	clientConfig.BaseURL = config.BaseURL
+	return newChatProvider(ProviderCompatible, config, clientConfig), nil
+}
+
+// chatProvider completes chats through the OpenAI chat completion API
+type chatProvider struct {
+	name        string
+	model       string
+	temperature float32
+	client      *openai.Client
+}
+
+// newChatProvider creates a chat provider with the request timeout applied to its HTTP client
+func newChatProvider(name string, config Config, clientConfig openai.ClientConfig) *chatProvider {
+	clientConfig.HTTPClient = &http.Client{Timeout: config.Timeout}
+	return &chatProvider{
+		name:        name,
+		model:       config.Model,
+		temperature: float32(config.Temperature),
+		client:      openai.NewClientWithConfig(clientConfig),
+	}
+}
+
+// Complete returns the model answer to the prompts
+func (p *chatProvider) Complete(ctx context.Context, system string, user string) (string, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       p.model,
+		Temperature: p.temperature,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: system,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: user,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("%s returned no choices", p.name)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// chatRequest is the part of a chat completion request checked by the tests
+type chatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+}
+
+// newChatServer serves chat completions with the given status and body, recording the last request
+func newChatServer(t *testing.T, status int, body string, request *chatRequest, auth *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		*auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIComplete(t *testing.T) {
+	var request chatRequest
+	var auth string
+	server := newChatServer(t, http.StatusOK, `{"choices":[{"index":0,"message":{"role":"assistant","content":"export const x = 1;"}}]}`, &request, &auth)
+	provider, err := NewOpenAI(Config{Model: "gpt-test", BaseURL: server.URL + "/v1", ApiKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := provider.Complete(context.Background(), "system prompt", "user message")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "export const x = 1;" {
+		t.Fatalf("got answer %q", answer)
+	}
+	// The request carries the key, the model and both prompts in order
+	if auth != "Bearer sk-test" {
+		t.Fatalf("got authorization %q", auth)
+	}
+	if request.Model != "gpt-test" {
+		t.Fatalf("got model %q", request.Model)
+	}
+	if len(request.Messages) != 2 ||
+		request.Messages[0].Role != "system" || request.Messages[0].Content != "system prompt" ||
+		request.Messages[1].Role != "user" || request.Messages[1].Content != "user message" {
+		t.Fatalf("got messages %+v", request.Messages)
+	}
+}
+
+func TestOpenAINoChoices(t *testing.T) {
+	var request chatRequest
+	var auth string
+	server := newChatServer(t, http.StatusOK, `{"choices":[]}`, &request, &auth)
+	provider, err := NewOpenAI(Config{Model: "gpt-test", BaseURL: server.URL + "/v1", ApiKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Complete(context.Background(), "system", "user")
+	if err == nil || !strings.Contains(err.Error(), "no choices") {
+		t.Fatalf("got %v, want a no choices error", err)
+	}
+}
+
+func TestOpenAIErrorStatus(t *testing.T) {
+	var request chatRequest
+	var auth string
+	server := newChatServer(t, http.StatusUnauthorized, `{"error":{"message":"invalid api key","type":"invalid_request_error"}}`, &request, &auth)
+	provider, err := NewOpenAI(Config{Model: "gpt-test", BaseURL: server.URL + "/v1", ApiKey: "sk-bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Complete(context.Background(), "system", "user")
+	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("got %v, want the API error", err)
+	}
+}
+
+func TestOpenAIRequiresKey(t *testing.T) {
+	_, err := NewOpenAI(Config{Model: "gpt-test"})
+	if err == nil {
+		t.Fatal("expected an error without an API key")
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	_, err := New(Config{Provider: "other"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/urfave/cli/v2"
 	"log"
@@ -8,12 +9,35 @@ import (
 	"netsuite-companion/store"
 	"netsuite-companion/util"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
 func main() {
-	baseStore := store.NewBaseStore()
-	tree := file.CreateTree()
+	var baseStore *store.BaseStore
+	var tree *file.Tree
 	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "root",
+				Usage: "project root directory, found by walking up from the working directory by default",
+			},
+			&cli.StringSliceFlag{
+				Name:  "config",
+				Usage: "override a configuration value as key=value, may be repeated",
+			},
+		},
+		Before: func(cCtx *cli.Context) error {
+			root, err := projectRoot(cCtx)
+			if err != nil {
+				return err
+			}
+			baseStore = store.NewBaseStore(root)
+			tree = file.CreateTree(root)
+			tree.SetSecrets(baseStore.Secrets())
+			return baseStore.SetFlagOverrides(cCtx.StringSlice("config"))
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "init",
@@ -25,14 +49,23 @@ func main() {
 						Usage:   "force global initialization",
 						Aliases: []string{"f"},
 					},
+					&cli.StringFlag{
+						Name:  "api-version",
+						Usage: "SuiteScript API version the tsconfig.json targets",
+						Value: store.DefaultApiVersion,
+					},
 				},
 				Action: func(cCtx *cli.Context) error {
+					apiVersion := cCtx.String("api-version")
+					if !slices.Contains(store.ApiVersions(), apiVersion) {
+						return fmt.Errorf("unknown api version %s, expected one of %v", apiVersion, store.ApiVersions())
+					}
 					force := cCtx.Bool("force")
 					err := baseStore.CreateGlobal(force)
 					if err != nil {
 						return err
 					}
-					err = tree.Build()
+					err = tree.Build(apiVersion)
 					if err != nil {
 						return err
 					}
@@ -43,7 +76,25 @@ func main() {
 				Name:    "add",
 				Aliases: []string{"a"},
 				Usage:   "Add a project, or create script, module, or TS type files",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "create every script listed in a YAML or JSON spec file",
+					},
+				},
 				Action: func(cCtx *cli.Context) error {
+					from := cCtx.String("from")
+					if from != "" {
+						global, err := baseStore.RetrieveGlobal()
+						if err != nil {
+							return err
+						}
+						project, err := baseStore.RetrieveProject()
+						if err != nil {
+							return err
+						}
+						return tree.CreateFromSpec(global, project, from)
+					}
 					fmt.Println("Please select one of the following options:")
 					for _, option := range util.GetOptions() {
 						fmt.Printf("  - %s\n", option)
@@ -54,35 +105,37 @@ func main() {
 					{
 						Name:  "project",
 						Usage: "Add a new project to organize your code",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "name",
+								Usage:   "project name",
+								Aliases: []string{"n"},
+							},
+							&cli.StringFlag{
+								Name:    "description",
+								Usage:   "project description",
+								Aliases: []string{"d"},
+							},
+							&cli.StringFlag{
+								Name:  "api-version",
+								Usage: "default SuiteScript API version of the project scripts",
+							},
+						},
 						Action: func(cCtx *cli.Context) error {
-							err := baseStore.CreateProject()
-							if err != nil {
-								return err
-							}
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
 							}
-							project, err := baseStore.RetrieveProject()
-							if err != nil {
-								return err
-							}
-							err = tree.CreateProjectFolder(global, project)
-							if err != nil {
-								return err
-							}
-							err = tree.CreateManifest(project)
-							if err != nil {
-								return err
-							}
-							return nil
+							_, err = tree.AddProject(global, baseStore, cCtx.String("name"), cCtx.String("description"), cCtx.String("api-version"))
+							return err
 						},
 					},
 					{
 						Name:  "bundle",
 						Usage: "Add a new bundle script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -91,7 +144,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateBundle(global, project, instruct)
+							err = tree.CreateBundle(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -101,8 +154,9 @@ func main() {
 					{
 						Name:  "client",
 						Usage: "Add a new client script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -111,7 +165,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateClient(global, project, instruct)
+							err = tree.CreateClient(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -121,8 +175,9 @@ func main() {
 					{
 						Name:  "formclient",
 						Usage: "Add a new form client script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -131,7 +186,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateFormClient(global, project, instruct)
+							err = tree.CreateFormClient(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -141,8 +196,9 @@ func main() {
 					{
 						Name:  "mapreduce",
 						Usage: "Add a new map reduce script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -151,7 +207,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateMapReduce(global, project, instruct)
+							err = tree.CreateMapReduce(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -161,8 +217,9 @@ func main() {
 					{
 						Name:  "massupdate",
 						Usage: "Add a new mass update script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -171,7 +228,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateMassUpdate(global, project, instruct)
+							err = tree.CreateMassUpdate(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -181,8 +238,9 @@ func main() {
 					{
 						Name:  "portlet",
 						Usage: "Add a new portlet script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -191,7 +249,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreatePortlet(global, project, instruct)
+							err = tree.CreatePortlet(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -201,8 +259,9 @@ func main() {
 					{
 						Name:  "restlet",
 						Usage: "Add a new restlet script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -211,7 +270,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateRestlet(global, project, instruct)
+							err = tree.CreateRestlet(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -221,8 +280,9 @@ func main() {
 					{
 						Name:  "scheduled",
 						Usage: "Add a new scheduled script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -231,7 +291,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateScheduled(global, project, instruct)
+							err = tree.CreateScheduled(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -241,8 +301,9 @@ func main() {
 					{
 						Name:  "suitelet",
 						Usage: "Add a new suitelet script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -251,7 +312,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateSuitelet(global, project, instruct)
+							err = tree.CreateSuitelet(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -261,8 +322,9 @@ func main() {
 					{
 						Name:  "userevent",
 						Usage: "Add a new user event script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -271,7 +333,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateUserEvent(global, project, instruct)
+							err = tree.CreateUserEvent(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -281,8 +343,9 @@ func main() {
 					{
 						Name:  "workflowaction",
 						Usage: "Add a new workflow action script",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -291,7 +354,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateWorkflowAction(global, project, instruct)
+							err = tree.CreateWorkflowAction(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -301,8 +364,9 @@ func main() {
 					{
 						Name:  "module",
 						Usage: "Add a module file",
+						Flags: scriptFlags(),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							opts := scriptOptions(cCtx)
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -311,7 +375,7 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateModule(global, project, instruct)
+							err = tree.CreateModule(global, project, opts)
 							if err != nil {
 								return err
 							}
@@ -321,8 +385,494 @@ func main() {
 					{
 						Name:  "type",
 						Usage: "Add a TypeScript type",
+						Flags: scriptFlags(),
+						Action: func(cCtx *cli.Context) error {
+							opts := scriptOptions(cCtx)
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							err = tree.CreateType(global, project, opts)
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+					{
+						Name:  "field",
+						Usage: "Add a custom body, column, entity or item field",
+						Subcommands: fieldCommands(func(kind string, opts *file.FieldOptions) error {
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							return tree.CreateField(global, project, kind, opts)
+						}),
+					},
+					{
+						Name:  "record",
+						Usage: "Add a custom record type with a TypeScript accessor",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "name",
+								Usage:   "record name",
+								Aliases: []string{"n"},
+							},
+							&cli.StringFlag{
+								Name:    "description",
+								Usage:   "record description",
+								Aliases: []string{"d"},
+							},
+							&cli.StringFlag{
+								Name:  "script-id",
+								Usage: "override the generated customrecord_ id",
+							},
+							&cli.StringSliceFlag{
+								Name:  "field",
+								Usage: "field definition as id:label:type[:mandatory[:source]], may be repeated",
+							},
+							&cli.BoolFlag{
+								Name:    "yes",
+								Usage:   "use defaults instead of prompting for missing values",
+								Aliases: []string{"y"},
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							opts := &file.RecordOptions{
+								Name:        cCtx.String("name"),
+								Description: cCtx.String("description"),
+								ScriptId:    cCtx.String("script-id"),
+								Fields:      cCtx.StringSlice("field"),
+								Yes:         cCtx.Bool("yes"),
+							}
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							err = tree.CreateRecord(global, project, opts)
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "param",
+						Usage:     "Add a parameter to an existing script and its typed accessor",
+						ArgsUsage: "<script>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "id",
+								Usage: "parameter id, the custscript_ and vendor prefixes are added when missing",
+							},
+							&cli.StringFlag{
+								Name:    "label",
+								Usage:   "parameter label",
+								Aliases: []string{"l"},
+							},
+							&cli.StringFlag{
+								Name:    "type",
+								Usage:   "parameter type such as text, checkbox, integer, date or select",
+								Aliases: []string{"t"},
+							},
+							&cli.StringFlag{
+								Name:  "default",
+								Usage: "default value",
+							},
+							&cli.StringFlag{
+								Name:  "source",
+								Usage: "list or record source of select parameters",
+							},
+							&cli.BoolFlag{
+								Name:    "yes",
+								Usage:   "use defaults instead of prompting for missing values",
+								Aliases: []string{"y"},
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							err := checkFlagsFirst(cCtx, "<script>")
+							if err != nil {
+								return err
+							}
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a script file")
+							}
+							opts := &file.ParamOptions{
+								Id:      cCtx.String("id"),
+								Label:   cCtx.String("label"),
+								Type:    cCtx.String("type"),
+								Default: cCtx.String("default"),
+								Source:  cCtx.String("source"),
+								Yes:     cCtx.Bool("yes"),
+							}
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							return tree.CreateParam(global, project, cCtx.Args().First(), opts)
+						},
+					},
+				},
+			},
+			{
+				Name:  "project",
+				Usage: "Manage the projects of this repository",
+				Subcommands: []*cli.Command{
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List the projects, marking the current one",
+						Action: func(cCtx *cli.Context) error {
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							var rows [][]string
+							for _, info := range project.Projects {
+								current := ""
+								if info.Name == project.Current {
+									current = "*"
+								}
+								rows = append(rows, []string{current, info.Name, info.ApiVersion, info.Created, info.Description})
+							}
+							util.PrintTable([]string{"", "NAME", "API", "CREATED", "DESCRIPTION"}, rows)
+							return nil
+						},
+					},
+					{
+						Name:      "use",
+						Usage:     "Make a project the current one",
+						ArgsUsage: "<name>",
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a project name")
+							}
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.UseProject(cCtx.Args().First())
+							if err != nil {
+								return err
+							}
+							err = tree.CreateManifest(project)
+							if err != nil {
+								return err
+							}
+							return tree.UpdateTsConfig(global, project)
+						},
+					},
+					{
+						Name:      "rename",
+						Usage:     "Rename a project, its folder and the references to it",
+						ArgsUsage: "<name> <new name>",
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 2 {
+								return fmt.Errorf("expected a project name and a new name")
+							}
+							name, newName := cCtx.Args().Get(0), cCtx.Args().Get(1)
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							_, err = tree.RenameProject(global, baseStore, name, newName)
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"rm"},
+						Usage:     "Remove a project and its folder",
+						ArgsUsage: "<name>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "keep-files",
+								Usage: "keep the project folder on disk",
+							},
+							&cli.BoolFlag{
+								Name:    "yes",
+								Usage:   "remove without asking for confirmation",
+								Aliases: []string{"y"},
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a project name")
+							}
+							name := cCtx.Args().First()
+							global, err := baseStore.RetrieveGlobal()
+							if err != nil {
+								return err
+							}
+							project, err := baseStore.RetrieveProject()
+							if err != nil {
+								return err
+							}
+							if project.Find(name) == nil {
+								return fmt.Errorf("project %s not found", name)
+							}
+							if !cCtx.Bool("keep-files") {
+								// List the objects removed along with the scripts
+								objects, err := tree.ProjectObjects(global, name)
+								if err != nil {
+									return err
+								}
+								for _, object := range objects {
+									fmt.Printf("Object %s refers to a script of %s\n", object, name)
+								}
+								ok, err := util.Confirm(fmt.Sprintf("Remove project %s, all of its files and %d objects?", name, len(objects)), cCtx.Bool("yes"))
+								if err != nil {
+									return err
+								}
+								if !ok {
+									return nil
+								}
+							}
+							_, err = tree.RemoveProject(global, baseStore, name, cCtx.Bool("keep-files"))
+							if err != nil {
+								return err
+							}
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Inspect or change the layered configuration",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "get",
+						Usage:     "Show a configuration value",
+						ArgsUsage: "<key>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "show-origin",
+								Usage: "show where the value came from",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a configuration key")
+							}
+							value, err := baseStore.GetConfig(cCtx.Args().First())
+							if err != nil {
+								return err
+							}
+							if cCtx.Bool("show-origin") {
+								fmt.Printf("%s\t%s\n", maskConfig(value.Key, value.Value), value.Origin)
+								return nil
+							}
+							fmt.Println(maskConfig(value.Key, value.Value))
+							return nil
+						},
+					},
+					{
+						Name:      "set",
+						Usage:     "Set a configuration value for this project, or globally",
+						ArgsUsage: "<key> <value>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "global",
+								Usage: "set the value in ~/.nsc instead of the project",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 2 {
+								return fmt.Errorf("expected a configuration key and value")
+							}
+							return baseStore.SetConfig(cCtx.Args().Get(0), cCtx.Args().Get(1), cCtx.Bool("global"))
+						},
+					},
+					{
+						Name:      "unset",
+						Usage:     "Remove a configuration value from this project, or globally",
+						ArgsUsage: "<key>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "global",
+								Usage: "remove the value from ~/.nsc instead of the project",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a configuration key")
+							}
+							return baseStore.SetConfig(cCtx.Args().First(), "", cCtx.Bool("global"))
+						},
+					},
+					{
+						Name:    "list",
+						Aliases: []string{"ls"},
+						Usage:   "List every configuration value",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "show-origin",
+								Usage: "show where each value came from",
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							values, err := baseStore.ListConfig()
+							if err != nil {
+								return err
+							}
+							headers := []string{"KEY", "VALUE"}
+							if cCtx.Bool("show-origin") {
+								headers = append(headers, "ORIGIN")
+							}
+							var rows [][]string
+							for _, value := range values {
+								row := []string{value.Key, maskConfig(value.Key, value.Value)}
+								if cCtx.Bool("show-origin") {
+									row = append(row, value.Origin)
+								}
+								rows = append(rows, row)
+							}
+							util.PrintTable(headers, rows)
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List the scripts, deployments and objects of the current project",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "print the inventory as JSON",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					global, err := baseStore.RetrieveGlobal()
+					if err != nil {
+						return err
+					}
+					project, err := baseStore.RetrieveProject()
+					if err != nil {
+						return err
+					}
+					entries, err := tree.Inventory(global, project)
+					if err != nil {
+						return err
+					}
+					if cCtx.Bool("json") {
+						content, err := json.MarshalIndent(entries, "", "  ")
+						if err != nil {
+							return err
+						}
+						fmt.Println(string(content))
+						return nil
+					}
+					var rows [][]string
+					for _, entry := range entries {
+						object := "no"
+						if entry.HasObject {
+							object = "yes"
+						}
+						path := entry.Path
+						if path == "" {
+							path = entry.Object
+						}
+						rows = append(rows, []string{entry.Type, entry.Name, entry.ScriptId, strings.Join(entry.DeploymentIds, ", "), path, object})
+					}
+					util.PrintTable([]string{"TYPE", "NAME", "SCRIPT ID", "DEPLOYMENTS", "FILE", "OBJECT"}, rows)
+					return nil
+				},
+			},
+			{
+				Name:      "mv",
+				Usage:     "Rename a script with its object, ids, header and imports",
+				ArgsUsage: "<script> <new name>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "print the planned edits without writing anything",
+					},
+				},
+				Action: func(cCtx *cli.Context) error {
+					err := checkFlagsFirst(cCtx, "<script>")
+					if err != nil {
+						return err
+					}
+					if cCtx.NArg() != 2 {
+						return fmt.Errorf("expected a script and its new name")
+					}
+					global, err := baseStore.RetrieveGlobal()
+					if err != nil {
+						return err
+					}
+					project, err := baseStore.RetrieveProject()
+					if err != nil {
+						return err
+					}
+					return tree.MoveScript(global, project, cCtx.Args().Get(0), cCtx.Args().Get(1), cCtx.Bool("dry-run"))
+				},
+			},
+			{
+				Name:  "validate",
+				Usage: "Check the SDF objects against the FileCabinet files, exiting non-zero on problems",
+				Action: func(cCtx *cli.Context) error {
+					global, err := baseStore.RetrieveGlobal()
+					if err != nil {
+						return err
+					}
+					diagnostics, err := tree.Validate(global)
+					if err != nil {
+						return err
+					}
+					for _, diagnostic := range diagnostics {
+						fmt.Println(diagnostic)
+					}
+					if len(diagnostics) > 0 {
+						return fmt.Errorf("%d problems found", len(diagnostics))
+					}
+					fmt.Println("No problems found")
+					return nil
+				},
+			},
+			{
+				Name:  "ai",
+				Usage: "Revise existing files with the inference provider",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "edit",
+						Usage:     "Revise a script with instructions, showing the change before applying it",
+						ArgsUsage: "<file>",
+						Flags: append(inferenceFlags(), &cli.BoolFlag{
+							Name:    "yes",
+							Usage:   "apply the change without asking",
+							Aliases: []string{"y"},
+						}),
 						Action: func(cCtx *cli.Context) error {
-							instruct := cCtx.String("instruct")
+							err := checkFlagsFirst(cCtx, "<file>")
+							if err != nil {
+								return err
+							}
+							if cCtx.NArg() != 1 {
+								return fmt.Errorf("expected a file to edit")
+							}
 							global, err := baseStore.RetrieveGlobal()
 							if err != nil {
 								return err
@@ -331,10 +881,46 @@ func main() {
 							if err != nil {
 								return err
 							}
-							err = tree.CreateType(global, project, instruct)
+							return tree.EditScript(global, project, cCtx.Args().First(), scriptOptions(cCtx))
+						},
+					},
+				},
+			},
+			{
+				Name:  "templates",
+				Usage: "Inspect or customize the script templates",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export the built-in templates to customize them",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "dir",
+								Usage: "destination directory, defaults to ./.nsc-templates",
+							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Usage:   "overwrite existing template files",
+								Aliases: []string{"f"},
+							},
+						},
+						Action: func(cCtx *cli.Context) error {
+							return tree.ExportTemplates(cCtx.String("dir"), cCtx.Bool("force"))
+						},
+					},
+					{
+						Name:  "list",
+						Usage: "Show which source each template resolves to",
+						Action: func(cCtx *cli.Context) error {
+							infos, err := tree.ListTemplates()
 							if err != nil {
 								return err
 							}
+							var rows [][]string
+							for _, info := range infos {
+								rows = append(rows, []string{info.Name, info.Source, info.Path})
+							}
+							util.PrintTable([]string{"TEMPLATE", "SOURCE", "PATH"}, rows)
 							return nil
 						},
 					},
@@ -347,3 +933,311 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// projectRoot returns the --root directory, or the nearest project root above
+// the working directory. Commands that can run outside a project fall back to
+// the working directory, and init always starts there.
+func projectRoot(cCtx *cli.Context) (string, error) {
+	// Use the root flag when set
+	if root := cCtx.String("root"); root != "" {
+		if !util.Exists(root) {
+			return "", fmt.Errorf("root %s does not exist", root)
+		}
+		return filepath.Abs(root)
+	}
+	// Get the current working directory
+	dirname, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	command := cCtx.Args().First()
+	switch command {
+	case "init", "i":
+		return dirname, nil
+	}
+	// Walk up to the project root
+	root, err := store.FindRoot(dirname)
+	if err != nil {
+		switch command {
+		case "", "help", "h", "templates":
+			return dirname, nil
+		}
+		return "", err
+	}
+	return root, nil
+}
+
+// checkFlagsFirst fails when a flag follows the positional arguments, which
+// would otherwise be taken as an argument and silently ignored
+func checkFlagsFirst(cCtx *cli.Context, positional string) error {
+	for _, arg := range cCtx.Args().Slice() {
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			// Name the command with its parents, as in nsc add param
+			var names []string
+			for _, c := range cCtx.Lineage() {
+				if c.Command != nil && c.Command.Name != "" {
+					names = append([]string{c.Command.Name}, names...)
+				}
+			}
+			return fmt.Errorf("flags must come before %s, as in %s [options] %s, found %s after it",
+				positional, strings.Join(names, " "), cCtx.Command.ArgsUsage, arg)
+		}
+	}
+	return nil
+}
+
+// maskConfig hides all but the last characters of secret configuration values
+func maskConfig(key string, value string) string {
+	if !strings.HasSuffix(key, "_key") || len(value) <= 4 {
+		return value
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+// scriptFlags returns the flags shared by every script subcommand
+func scriptFlags() []cli.Flag {
+	return append([]cli.Flag{
+		&cli.StringFlag{
+			Name:    "name",
+			Usage:   "file name",
+			Aliases: []string{"n"},
+		},
+		&cli.StringFlag{
+			Name:    "description",
+			Usage:   "file description",
+			Aliases: []string{"d"},
+		},
+		&cli.StringFlag{
+			Name:  "script-id",
+			Usage: "override the generated customscript_ id",
+		},
+		&cli.StringFlag{
+			Name:  "deployment-id",
+			Usage: "override the generated customdeploy_ id",
+		},
+		&cli.StringFlag{
+			Name:  "api-version",
+			Usage: "override the project SuiteScript API version: 2.0, 2.x or 2.1",
+		},
+		&cli.StringSliceFlag{
+			Name:    "entry",
+			Usage:   "entry points to generate, for example pageInit,saveRecord",
+			Aliases: []string{"e"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "param",
+			Usage:   "script parameter as id:label:type[:default[:source]], may be repeated",
+			Aliases: []string{"p"},
+		},
+		&cli.StringSliceFlag{
+			Name:     "record-type",
+			Usage:    "record types to deploy to, one deployment each, for example salesorder,customrecord_acm_label",
+			Category: "deployment",
+		},
+		&cli.StringSliceFlag{
+			Name:     "role",
+			Usage:    "audience roles, or all for every role",
+			Category: "deployment",
+		},
+		&cli.StringSliceFlag{
+			Name:     "department",
+			Usage:    "audience departments",
+			Category: "deployment",
+		},
+		&cli.StringSliceFlag{
+			Name:     "subsidiary",
+			Usage:    "audience subsidiaries",
+			Category: "deployment",
+		},
+		&cli.StringFlag{
+			Name:     "log-level",
+			Usage:    "deployment log level: DEBUG, AUDIT, ERROR or EMERGENCY",
+			Category: "deployment",
+		},
+		&cli.StringFlag{
+			Name:     "status",
+			Usage:    "deployment status, for example RELEASED, TESTING or NOTSCHEDULED",
+			Category: "deployment",
+		},
+		&cli.StringFlag{
+			Name:     "run-as-role",
+			Usage:    "role the script executes as",
+			Category: "deployment",
+		},
+		&cli.StringFlag{
+			Name:     "event-type",
+			Usage:    "user event type the deployment runs on, for example CREATE or EDIT",
+			Category: "deployment",
+		},
+		&cli.BoolFlag{
+			Name:    "yes",
+			Usage:   "use defaults instead of prompting for missing values",
+			Aliases: []string{"y"},
+		},
+		&cli.BoolFlag{
+			Name:    "force",
+			Usage:   "overwrite existing files, keeping a backup in .nsc-backup",
+			Aliases: []string{"f"},
+		},
+	}, append(inferenceFlags(),
+		&cli.BoolFlag{
+			Name:     "preview",
+			Usage:    "show the template and model output side by side before writing",
+			Category: "inference",
+		},
+	)...)
+}
+
+// inferenceFlags returns the flags selecting and feeding the inference provider
+func inferenceFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "instruct",
+			Usage:    "instructions to run the generated file through the inference provider",
+			Aliases:  []string{"i"},
+			Category: "inference",
+		},
+		&cli.StringFlag{
+			Name:     "instruct-file",
+			Usage:    "file holding instructions for the inference provider",
+			Category: "inference",
+		},
+		&cli.StringFlag{
+			Name:     "model",
+			Usage:    "override the configured inference model",
+			Category: "inference",
+		},
+		&cli.StringFlag{
+			Name:     "provider",
+			Usage:    "override the configured inference provider: openai, azure or compatible",
+			Category: "inference",
+		},
+		&cli.BoolFlag{
+			Name:     "context",
+			Usage:    "send the project types, modules, field constants and custom objects as context",
+			Category: "inference",
+		},
+		&cli.StringSliceFlag{
+			Name:     "context-file",
+			Usage:    "send a file as context, relative to the project root",
+			Category: "inference",
+		},
+		&cli.BoolFlag{
+			Name:     "no-cache",
+			Usage:    "call the inference provider even when a cached response exists, and do not store it",
+			Category: "inference",
+		},
+	}
+}
+
+// scriptOptions builds the script options from the command flags
+func scriptOptions(cCtx *cli.Context) *file.ScriptOptions {
+	opts := &file.ScriptOptions{
+		Name:         cCtx.String("name"),
+		Description:  cCtx.String("description"),
+		ScriptId:     cCtx.String("script-id"),
+		DeploymentId: cCtx.String("deployment-id"),
+		ApiVersion:   cCtx.String("api-version"),
+		Entries:      cCtx.StringSlice("entry"),
+		Params:       cCtx.StringSlice("param"),
+		Force:        cCtx.Bool("force"),
+		Instruct:     cCtx.String("instruct"),
+		InstructFile: cCtx.String("instruct-file"),
+		Model:        cCtx.String("model"),
+		Provider:     cCtx.String("provider"),
+		Preview:      cCtx.Bool("preview"),
+		Context:      cCtx.Bool("context"),
+		ContextFiles: cCtx.StringSlice("context-file"),
+		NoCache:      cCtx.Bool("no-cache"),
+		Yes:          cCtx.Bool("yes"),
+	}
+	// Collect the deployment settings
+	deployment := file.DeploymentOptions{
+		RecordTypes:  cCtx.StringSlice("record-type"),
+		Roles:        cCtx.StringSlice("role"),
+		Departments:  cCtx.StringSlice("department"),
+		Subsidiaries: cCtx.StringSlice("subsidiary"),
+		LogLevel:     cCtx.String("log-level"),
+		Status:       cCtx.String("status"),
+		RunAsRole:    cCtx.String("run-as-role"),
+		EventType:    cCtx.String("event-type"),
+	}
+	if !deployment.Empty() {
+		opts.Deployments = []file.DeploymentOptions{deployment}
+	}
+	return opts
+}
+
+// fieldCommands returns a subcommand for each custom field kind, calling create with the collected options
+func fieldCommands(create func(kind string, opts *file.FieldOptions) error) []*cli.Command {
+	var commands []*cli.Command
+	for _, kind := range file.FieldKinds() {
+		kind := kind
+		commands = append(commands, &cli.Command{
+			Name:  kind,
+			Usage: fmt.Sprintf("Add a custom %s field", kind),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "id",
+					Usage: "field id, the kind and vendor prefixes are added when missing",
+				},
+				&cli.StringFlag{
+					Name:    "label",
+					Usage:   "field label",
+					Aliases: []string{"l"},
+				},
+				&cli.StringFlag{
+					Name:    "description",
+					Usage:   "field description",
+					Aliases: []string{"d"},
+				},
+				&cli.StringFlag{
+					Name:    "type",
+					Usage:   "field type such as text, checkbox, date or select",
+					Aliases: []string{"t"},
+				},
+				&cli.StringFlag{
+					Name:  "display",
+					Usage: "display type such as normal, hidden, inline or disabled",
+				},
+				&cli.StringFlag{
+					Name:  "source",
+					Usage: "list or record source of select fields",
+				},
+				&cli.StringFlag{
+					Name:  "help-text",
+					Usage: "field help text",
+				},
+				&cli.StringSliceFlag{
+					Name:  "applies-to",
+					Usage: fmt.Sprintf("records the field applies to: %s", strings.Join(file.FieldAppliesTo(kind), ", ")),
+				},
+				&cli.BoolFlag{
+					Name:  "mandatory",
+					Usage: "make the field mandatory",
+				},
+				&cli.BoolFlag{
+					Name:    "yes",
+					Usage:   "use defaults instead of prompting for missing values",
+					Aliases: []string{"y"},
+				},
+			},
+			Action: func(cCtx *cli.Context) error {
+				opts := &file.FieldOptions{
+					Id:          cCtx.String("id"),
+					Label:       cCtx.String("label"),
+					Description: cCtx.String("description"),
+					Type:        cCtx.String("type"),
+					DisplayType: cCtx.String("display"),
+					Help:        cCtx.String("help-text"),
+					Source:      cCtx.String("source"),
+					AppliesTo:   cCtx.StringSlice("applies-to"),
+					Mandatory:   cCtx.Bool("mandatory"),
+					Yes:         cCtx.Bool("yes"),
+				}
+				return create(kind, opts)
+			},
+		})
+	}
+	return commands
+}
@@ -0,0 +1,313 @@
+package store
+
+import (
+	_ "embed"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"netsuite-companion/util"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Built-in configuration defaults
+//
+//go:embed defaults.yaml
+var defaultConfig []byte
+
+// Prefix of environment variables overriding configuration values
+const envPrefix = "NSC_"
+
+// ConfigValue represents a resolved configuration value
+type ConfigValue struct {
+	// Configuration key
+	Key string
+	// Resolved value
+	Value string
+	// Layer the value came from
+	Origin string
+}
+
+// configLayer represents one source of configuration values
+type configLayer struct {
+	origin string
+	values map[string]string
+}
+
+// ConfigKeys returns the keys of every configuration value
+func ConfigKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(GlobalStore{})
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, configKey(t.Field(i)))
+	}
+	return keys
+}
+
+// SetFlagOverrides sets configuration values given on the command line as key=value
+func (s *BaseStore) SetFlagOverrides(pairs []string) error {
+	s.flags = map[string]string{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid config %q, expected key=value", pair)
+		}
+		if err := checkConfigValue(key, value); err != nil {
+			return err
+		}
+		s.flags[key] = value
+	}
+	return nil
+}
+
+// ListConfig resolves every configuration value along with its origin
+func (s *BaseStore) ListConfig() ([]ConfigValue, error) {
+	// Read every layer
+	layers, err := s.configLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Let each layer override the previous ones
+	var values []ConfigValue
+	for _, key := range ConfigKeys() {
+		value := ConfigValue{Key: key, Origin: "unset"}
+		for _, layer := range layers {
+			if v, ok := layer.values[key]; ok {
+				value.Value, value.Origin = v, layer.origin
+			}
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// GetConfig resolves one configuration value along with its origin
+func (s *BaseStore) GetConfig(key string) (*ConfigValue, error) {
+	// Check the key
+	if err := checkConfigValue(key, ""); err != nil {
+		return nil, err
+	}
+	// Resolve every value and pick the requested one
+	values, err := s.ListConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range values {
+		if value.Key == key {
+			return &value, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown config key %s", key)
+}
+
+// SetConfig sets a configuration value in the project store, or in the global
+// store when global is set. An empty value removes a project override. The
+// API key always goes to the secret backend.
+func (s *BaseStore) SetConfig(key string, value string, global bool) error {
+	// Check the key and value
+	if err := checkConfigValue(key, value); err != nil {
+		return err
+	}
+
+	// Keep secrets in the secret backend
+	if key == openAIApiKeySecret {
+		return s.Secrets().Set(key, value)
+	}
+
+	// Update the global store file
+	if global {
+		return s.setGlobalConfig(key, value)
+	}
+
+	// Update the project overrides
+	project, err := s.RetrieveProject()
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		delete(project.Overrides, key)
+	} else {
+		if project.Overrides == nil {
+			project.Overrides = map[string]string{}
+		}
+		project.Overrides[key] = value
+	}
+	return s.UpdateProject(project)
+}
+
+// setGlobalConfig sets a configuration value in the global store file
+func (s *BaseStore) setGlobalConfig(key string, value string) error {
+	// Read the raw global store file
+	path, err := s.getGlobalPath()
+	if err != nil {
+		return err
+	}
+	store, err := s.readGlobalFile(path)
+	if err != nil {
+		return err
+	}
+	// Set the value and save the file
+	if err := setConfigField(store, key, value); err != nil {
+		return err
+	}
+	return s.UpdateGlobal(store)
+}
+
+// resolveGlobal merges every configuration layer into a global store
+func (s *BaseStore) resolveGlobal() (*GlobalStore, error) {
+	// Resolve every value
+	values, err := s.ListConfig()
+	if err != nil {
+		return nil, err
+	}
+	// Set each value on the store
+	store := &GlobalStore{}
+	for _, value := range values {
+		if err := setConfigField(store, value.Key, value.Value); err != nil {
+			return nil, fmt.Errorf("%s from %s: %w", value.Key, value.Origin, err)
+		}
+	}
+	return store, nil
+}
+
+// configLayers reads the configuration layers from lowest to highest precedence
+func (s *BaseStore) configLayers() ([]configLayer, error) {
+	var layers []configLayer
+
+	// Read the built-in defaults
+	defaults := map[string]string{}
+	if err := yaml.Unmarshal(defaultConfig, &defaults); err != nil {
+		return nil, err
+	}
+	layers = append(layers, configLayer{"default", nonEmpty(defaults)})
+
+	// Read the global store file
+	globalPath, err := s.getGlobalPath()
+	if err != nil {
+		return nil, err
+	}
+	global, err := s.readGlobalFile(globalPath)
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, configLayer{"global " + globalPath, configValues(global)})
+
+	// Read the project overrides, if there is a project
+	projectPath, err := s.getProjectPath()
+	if err != nil {
+		return nil, err
+	}
+	if s.root != "" && util.Exists(projectPath) {
+		project, err := s.readProjectFile(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, configLayer{"project " + projectPath, nonEmpty(project.Overrides)})
+	}
+
+	// Read the environment, one layer per variable to name it as the origin
+	for _, key := range ConfigKeys() {
+		name := envPrefix + strings.ToUpper(key)
+		if value := os.Getenv(name); value != "" {
+			layers = append(layers, configLayer{"env " + name, map[string]string{key: value}})
+		}
+	}
+
+	// Use the command line overrides last
+	layers = append(layers, configLayer{"flag --config", nonEmpty(s.flags)})
+	return layers, nil
+}
+
+// configValues returns the non-empty values of a global store by key
+func configValues(store *GlobalStore) map[string]string {
+	values := map[string]string{}
+	v := reflect.ValueOf(store).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		value := fmt.Sprint(v.Field(i).Interface())
+		if !v.Field(i).IsZero() {
+			values[configKey(v.Type().Field(i))] = value
+		}
+	}
+	return values
+}
+
+// setConfigField sets the global store field with the given key from its text value
+func setConfigField(store *GlobalStore, key string, value string) error {
+	v := reflect.ValueOf(store).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if configKey(v.Type().Field(i)) != key {
+			continue
+		}
+		field := v.Field(i)
+		// Empty values reset the field
+		if value == "" {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		// Parse the value by field kind
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s must be true or false", key)
+			}
+			field.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s must be a whole number", key)
+			}
+			field.SetInt(int64(n))
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", key)
+			}
+			field.SetFloat(f)
+		default:
+			return fmt.Errorf("%s cannot be set from text", key)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown config key %s", key)
+}
+
+// checkConfigValue ensures a key exists and its value can be set
+func checkConfigValue(key string, value string) error {
+	store := &GlobalStore{}
+	if err := setConfigField(store, key, value); err != nil {
+		return err
+	}
+	if key == "vendor_prefix" && value != "" {
+		return (&BaseStore{}).validateVendorPrefix(value)
+	}
+	if key == "vendor_name" && value != "" {
+		return checkFolderName("vendor name", value)
+	}
+	if key == "secret_backend" && value != "" && !slices.Contains(SecretBackends(), value) {
+		return fmt.Errorf("unknown secret backend %q, expected one of %v", value, SecretBackends())
+	}
+	return nil
+}
+
+// configKey returns the configuration key of a global store field
+func configKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	return key
+}
+
+// nonEmpty returns the values that are set
+func nonEmpty(values map[string]string) map[string]string {
+	result := map[string]string{}
+	for key, value := range values {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -23,32 +23,45 @@ func (s *BaseStore) CreateGlobal(force bool) error {
 			return err
 		}
 
-		// Set token for inference
-		token := os.Getenv("OPENAI_API_KEY")
-		if token != "" {
-			store.OpenAIApiKey = token
-		}
-
 		// Save the global store to the file
 		if err := s.saveToFile(path, store); err != nil {
 			return err
 		}
+
+		// Store the token for inference in the secret backend
+		token := os.Getenv(openAIApiKeyEnv)
+		if token != "" {
+			global, err := s.resolveGlobal()
+			if err != nil {
+				return err
+			}
+			if global.SecretBackend != secretBackendEnv {
+				if err := s.Secrets().Set(openAIApiKeySecret, token); err != nil {
+					return err
+				}
+			}
+			// Offer to encrypt a key the default backend left in plain text
+			if err := s.migrateSecrets(global); err != nil {
+				return err
+			}
+		}
 	}
 
 	// If no error occurred, return nil
 	return nil
 }
 
-// RetrieveGlobal retrieves the global store from the file
+// RetrieveGlobal retrieves the global store with the defaults, project
+// overrides, environment and command line values applied
 func (s *BaseStore) RetrieveGlobal() (*GlobalStore, error) {
-	// Get the path for the global store file
-	path, err := s.getGlobalPath()
+	// Resolve the configuration layers
+	store, err := s.resolveGlobal()
 	if err != nil {
 		return nil, err
 	}
 
-	// Read the global store file
-	store, err := s.readGlobalFile(path)
+	// Move a plain text API key into the secret backend
+	err = s.migrateSecrets(store)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +73,7 @@ func (s *BaseStore) RetrieveGlobal() (*GlobalStore, error) {
 // UpdateGlobal updates the global store in the file
 func (s *BaseStore) UpdateGlobal(store *GlobalStore) error {
 	// Get the path for the global store file
-	path, err := s.getProjectPath()
+	path, err := s.getGlobalPath()
 	if err != nil {
 		return err
 	}
@@ -97,6 +110,9 @@ func (s *BaseStore) collectGlobalInput() (*GlobalStore, error) {
 	if store.VendorName == "" {
 		return nil, fmt.Errorf("vendor name must be non-empty")
 	}
+	if err := checkFolderName("vendor name", store.VendorName); err != nil {
+		return nil, err
+	}
 
 	// Get the vendor prefix from the user
 	store.VendorPrefix = util.GetInput("Enter three letter vendor prefix:")
@@ -1,32 +1,67 @@
 package store
 
 import (
+	"fmt"
+	"gopkg.in/yaml.v3"
 	"netsuite-companion/util"
-	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
-// CreateProject creates a new project
-func (s *BaseStore) CreateProject() error {
+// Default SuiteScript API version of new projects
+const DefaultApiVersion = "2.x"
+
+// CreateProject creates a new project and makes it the current one, prompting
+// for the name when it is empty
+func (s *BaseStore) CreateProject(name string, description string, apiVersion string) error {
+	store, err := s.NewProject(name, description, apiVersion)
+	if err != nil {
+		return err
+	}
+	return s.SaveProject(store)
+}
+
+// NewProject returns the projects with a new current one added, prompting
+// for the name when it is empty, without saving them
+func (s *BaseStore) NewProject(name string, description string, apiVersion string) (*ProjectStore, error) {
 	// Get the path for the project file
 	path, err := s.getProjectPath()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Read the existing projects, if any
+	store := &ProjectStore{}
+	if util.Exists(path) {
+		store, err = s.readProjectFile(path)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Collect input for the project
-	store, err := s.collectProjectInput()
+	info, err := s.collectProjectInput(name, description, apiVersion)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if store.Find(info.Name) != nil {
+		return nil, fmt.Errorf("project %s already exists", info.Name)
 	}
+	store.Projects = append(store.Projects, info)
+	store.Current = info.Name
+	return store, nil
+}
 
-	// Save the project to the file
-	if err := s.saveToFile(path, store); err != nil {
+// SaveProject saves the projects, creating the project file when missing
+func (s *BaseStore) SaveProject(store *ProjectStore) error {
+	// Get the path for the project file
+	path, err := s.getProjectPath()
+	if err != nil {
 		return err
 	}
-
-	// If no error occurred, return nil
-	return nil
+	return s.saveToFile(path, store)
 }
 
 // RetrieveProject retrieves a project
@@ -65,26 +100,182 @@ func (s *BaseStore) UpdateProject(store *ProjectStore) error {
 	return nil
 }
 
+// UseProject makes an existing project the current one
+func (s *BaseStore) UseProject(name string) (*ProjectStore, error) {
+	// Read the projects
+	store, err := s.RetrieveProject()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the project exists and select it
+	if store.Find(name) == nil {
+		return nil, fmt.Errorf("project %s not found", name)
+	}
+	store.Current = name
+
+	// Save the projects
+	return store, s.UpdateProject(store)
+}
+
+// RenameProject renames a project, keeping it current if it was
+func (s *BaseStore) RenameProject(name string, newName string) (*ProjectStore, error) {
+	store, err := s.RenamedProject(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	// Save the projects
+	return store, s.UpdateProject(store)
+}
+
+// RenamedProject returns the projects with one renamed, without saving them
+func (s *BaseStore) RenamedProject(name string, newName string) (*ProjectStore, error) {
+	// Read the projects
+	store, err := s.RetrieveProject()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check both names
+	info := store.Find(name)
+	if info == nil {
+		return nil, fmt.Errorf("project %s not found", name)
+	}
+	if err := checkFolderName("project name", newName); err != nil {
+		return nil, err
+	}
+	if store.Find(newName) != nil {
+		return nil, fmt.Errorf("project %s already exists", newName)
+	}
+
+	// Rename the project
+	info.Name = newName
+	if store.Current == name {
+		store.Current = newName
+	}
+	return store, nil
+}
+
+// EncodeProject returns the path of the project file and its content for the given projects
+func (s *BaseStore) EncodeProject(store *ProjectStore) (string, string, error) {
+	path, err := s.getProjectPath()
+	if err != nil {
+		return "", "", err
+	}
+	content, err := yaml.Marshal(store)
+	if err != nil {
+		return "", "", err
+	}
+	return path, string(content), nil
+}
+
+// RemoveProject removes a project, selecting the first remaining one when it was current
+func (s *BaseStore) RemoveProject(name string) (*ProjectStore, error) {
+	store, err := s.RemovedProject(name)
+	if err != nil {
+		return nil, err
+	}
+	// Save the projects
+	return store, s.UpdateProject(store)
+}
+
+// RemovedProject returns the projects without the named one, without saving them
+func (s *BaseStore) RemovedProject(name string) (*ProjectStore, error) {
+	// Read the projects
+	store, err := s.RetrieveProject()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the project exists and remove it
+	if store.Find(name) == nil {
+		return nil, fmt.Errorf("project %s not found", name)
+	}
+	store.Projects = slices.DeleteFunc(store.Projects, func(info *ProjectInfo) bool {
+		return info.Name == name
+	})
+
+	// Select another project when the current one was removed
+	if store.Current == name {
+		store.Current = ""
+		if len(store.Projects) > 0 {
+			store.Current = store.Projects[0].Name
+		}
+	}
+	return store, nil
+}
+
+// Find returns the project with the given name, or nil when there is none
+func (p *ProjectStore) Find(name string) *ProjectInfo {
+	for _, info := range p.Projects {
+		if info.Name == name {
+			return info
+		}
+	}
+	return nil
+}
+
 // getProjectPath gets the path for the project file
 func (s *BaseStore) getProjectPath() (string, error) {
-	// Get the current working directory
-	dirname, err := os.Getwd()
+	// Join the project root with the store directory name
+	return filepath.Join(s.root, storeDirName), nil
+}
+
+// collectProjectInput collects input for the project
+func (s *BaseStore) collectProjectInput(name string, description string, apiVersion string) (*ProjectInfo, error) {
+	// Create a new project
+	info := &ProjectInfo{
+		Description: description,
+		Created:     time.Now().Format("2006-01-02"),
+		ApiVersion:  apiVersion,
+	}
+
+	// Get the project name from the argument or the user
+	current, err := util.AskInput(name, "Enter project name: ", "name", "", false)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	info.Name = current
+	if err := checkFolderName("project name", info.Name); err != nil {
+		return nil, err
 	}
 
-	// Join the directory name with the store directory name
-	return filepath.Join(dirname, storeDirName), nil
+	// Default and check the API version
+	if info.ApiVersion == "" {
+		info.ApiVersion = DefaultApiVersion
+	}
+	if !slices.Contains(ApiVersions(), info.ApiVersion) {
+		return nil, fmt.Errorf("unknown api version %s, expected one of %v", info.ApiVersion, ApiVersions())
+	}
+
+	// Return the project
+	return info, nil
 }
 
-// collectProjectInput collects input for the project
-func (s *BaseStore) collectProjectInput() (*ProjectStore, error) {
-	// Create a new project store
-	store := &ProjectStore{}
+// checkFolderName ensures a vendor or project name can name a single
+// FileCabinet folder
+func checkFolderName(kind string, name string) error {
+	switch strings.TrimSpace(name) {
+	case "":
+		return fmt.Errorf("%s must be non-empty", kind)
+	case ".", "..":
+		return fmt.Errorf("%s cannot be %q", kind, name)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("%s %q must not contain / or \\", kind, name)
+	}
+	return nil
+}
 
-	// Get the project name from the user
-	store.Current = util.GetInput("Enter project name: ")
+// ApiVersion returns the default API version of the current project
+func (p *ProjectStore) ApiVersion() string {
+	if info := p.Find(p.Current); info != nil && info.ApiVersion != "" {
+		return info.ApiVersion
+	}
+	return DefaultApiVersion
+}
 
-	// Return the project store
-	return store, nil
+// ApiVersions returns the supported SuiteScript API versions
+func ApiVersions() []string {
+	return []string{"2.0", "2.x", "2.1"}
 }
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+)
+
+// FindRoot walks up from dir to the nearest project root. A project root holds
+// the project store file, or an SDF source folder with a manifest or deploy file.
+func FindRoot(dir string) (string, error) {
+	// Get the user's home directory, where the store file is the global one
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	// Start from an absolute path
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		// Check the project store file, unless it is the global store
+		if current != home && util.Exists(filepath.Join(current, storeDirName)) {
+			return current, nil
+		}
+		// Check the SDF source folder
+		if util.Exists(filepath.Join(current, "src", "manifest.xml")) || util.Exists(filepath.Join(current, "src", "deploy.xml")) {
+			return current, nil
+		}
+		// Move to the parent until the filesystem root is reached
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("no project root found from %s, please run nsc init or set --root", dir)
+		}
+		current = parent
+	}
+}
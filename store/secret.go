@@ -0,0 +1,352 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/pbkdf2"
+	"gopkg.in/yaml.v3"
+	"netsuite-companion/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Constants
+const (
+	secretsFileName      = ".nsc-secrets"          // Encrypted secrets file in the home directory
+	secretPassphraseEnv  = "NSC_SECRET_PASSPHRASE" // Passphrase of the encrypted secrets file
+	secretKeyIterations  = 600000                  // PBKDF2 iterations deriving the encryption key
+	secretKeyLength      = 32                      // AES-256 key length
+	secretSaltLength     = 16                      // Salt length of the encrypted secrets file
+	secretBackendFile    = "file"                  // Secrets kept in plain text in ~/.nsc
+	secretBackendCrypt   = "encrypted"             // Secrets kept in a passphrase encrypted file
+	secretBackendEnv     = "env"                   // Secrets read from the environment only
+	openAIApiKeySecret   = "openai_api_key"        // Name of the OpenAI API key secret
+	openAIApiKeyEnv      = "OPENAI_API_KEY"        // Environment variable of the OpenAI API key
+	secretFilePermission = 0600                    // Secrets file permissions
+)
+
+// SecretBackend stores secret values outside of the configuration
+type SecretBackend interface {
+	Get(name string) (string, error)
+	Set(name string, value string) error
+}
+
+// SecretBackends returns the names of the supported secret backends
+func SecretBackends() []string {
+	return []string{secretBackendFile, secretBackendCrypt, secretBackendEnv}
+}
+
+// Secrets returns the secret backend selected in the configuration
+func (s *BaseStore) Secrets() SecretBackend {
+	return &selectedSecrets{store: s}
+}
+
+// selectedSecrets resolves the configured backend on first use
+type selectedSecrets struct {
+	store   *BaseStore
+	backend SecretBackend
+}
+
+// Get gets a secret from the configured backend
+func (s *selectedSecrets) Get(name string) (string, error) {
+	backend, err := s.resolve()
+	if err != nil {
+		return "", err
+	}
+	return backend.Get(name)
+}
+
+// Set stores a secret in the configured backend
+func (s *selectedSecrets) Set(name string, value string) error {
+	backend, err := s.resolve()
+	if err != nil {
+		return err
+	}
+	return backend.Set(name, value)
+}
+
+// resolve picks the backend named in the configuration
+func (s *selectedSecrets) resolve() (SecretBackend, error) {
+	if s.backend != nil {
+		return s.backend, nil
+	}
+	global, err := s.store.resolveGlobal()
+	if err != nil {
+		return nil, err
+	}
+	s.backend, err = s.store.newSecretBackend(global)
+	return s.backend, err
+}
+
+// newSecretBackend creates the secret backend named in the global store
+func (s *BaseStore) newSecretBackend(global *GlobalStore) (SecretBackend, error) {
+	switch global.SecretBackend {
+	case secretBackendFile:
+		return &fileSecrets{store: s, global: global}, nil
+	case secretBackendCrypt:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		return NewEncryptedSecrets(filepath.Join(home, secretsFileName), os.Getenv(secretPassphraseEnv)), nil
+	case secretBackendEnv:
+		return &envSecrets{}, nil
+	}
+	return nil, fmt.Errorf("unknown secret backend %q, expected one of %v", global.SecretBackend, SecretBackends())
+}
+
+// migrateSecrets moves a plain text API key out of ~/.nsc into the encrypted
+// backend. Under the default file backend the move is offered on first run,
+// and declining it records the file backend as an explicit choice. A key that
+// cannot be moved yet, for want of a passphrase, is reported on every run.
+func (s *BaseStore) migrateSecrets(global *GlobalStore) error {
+	// Read the raw global file
+	path, err := s.getGlobalPath()
+	if err != nil {
+		return err
+	}
+	raw, err := s.readGlobalFile(path)
+	if err != nil {
+		return err
+	}
+	if raw.OpenAIApiKey == "" {
+		return nil
+	}
+	// A passphrase is needed to move the key
+	unlockable := os.Getenv(secretPassphraseEnv) != "" || util.IsInteractive()
+	switch {
+	case global.SecretBackend == secretBackendCrypt:
+		if !unlockable {
+			fmt.Fprintf(os.Stderr, "Warning: openai_api_key is still in plain text in %s, set %s to move it to %s\n",
+				path, secretPassphraseEnv, secretsFileName)
+			return nil
+		}
+	case global.SecretBackend == secretBackendFile && raw.SecretBackend == "":
+		if !unlockable {
+			fmt.Fprintf(os.Stderr, "Warning: openai_api_key is stored in plain text in %s, set %s to move it to an "+
+				"encrypted file, or set secret_backend to file to keep it there\n", path, secretPassphraseEnv)
+			return nil
+		}
+		// Ask before switching backends, unless a passphrase was given up front
+		if os.Getenv(secretPassphraseEnv) == "" {
+			move, err := util.Confirm(fmt.Sprintf("openai_api_key is stored in plain text in %s. Move it to an encrypted file?", path), false)
+			if err != nil {
+				return err
+			}
+			if !move {
+				fmt.Printf("Keeping openai_api_key in %s, set secret_backend to encrypted to move it later\n", path)
+				raw.SecretBackend = secretBackendFile
+				return s.UpdateGlobal(raw)
+			}
+		}
+		raw.SecretBackend = secretBackendCrypt
+		global.SecretBackend = secretBackendCrypt
+	default:
+		// The file backend was chosen, and the env backend never reads ~/.nsc
+		return nil
+	}
+	// Store the key in the encrypted backend
+	backend, err := s.newSecretBackend(global)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Moving openai_api_key from %s to the %s secret backend\n", path, global.SecretBackend)
+	if err := backend.Set(openAIApiKeySecret, raw.OpenAIApiKey); err != nil {
+		return err
+	}
+	// Remove the plain text key
+	raw.OpenAIApiKey = ""
+	global.OpenAIApiKey = ""
+	return s.UpdateGlobal(raw)
+}
+
+// fileSecrets keeps secrets in plain text in the global store
+type fileSecrets struct {
+	store  *BaseStore
+	global *GlobalStore
+}
+
+// Get gets a secret from the resolved configuration
+func (f *fileSecrets) Get(name string) (string, error) {
+	for key, value := range configValues(f.global) {
+		if key == name {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// Set stores a secret in the global store file
+func (f *fileSecrets) Set(name string, value string) error {
+	err := f.store.setGlobalConfig(name, value)
+	if err != nil {
+		return err
+	}
+	// Keep later reads in step with the file
+	return setConfigField(f.global, name, value)
+}
+
+// envSecrets reads secrets from the environment and never persists them
+type envSecrets struct {
+}
+
+// Get gets a secret from NSC_<NAME>, or <NAME> when unset
+func (e *envSecrets) Get(name string) (string, error) {
+	if value := os.Getenv(envPrefix + strings.ToUpper(name)); value != "" {
+		return value, nil
+	}
+	return os.Getenv(strings.ToUpper(name)), nil
+}
+
+// Set refuses to store a secret
+func (e *envSecrets) Set(name string, value string) error {
+	return fmt.Errorf("the env secret backend never stores secrets, set %s%s instead", envPrefix, strings.ToUpper(name))
+}
+
+// EncryptedSecrets keeps secrets in a file encrypted with AES-GCM under a key
+// derived from a passphrase with PBKDF2-SHA256
+type EncryptedSecrets struct {
+	path       string
+	passphrase string
+}
+
+// encryptedFile represents the base64 encoded content of the encrypted secrets file
+type encryptedFile struct {
+	Salt  string `yaml:"salt"`
+	Nonce string `yaml:"nonce"`
+	Data  string `yaml:"data"`
+}
+
+// NewEncryptedSecrets creates an encrypted secrets backend for the file at
+// path. The passphrase is prompted for when empty.
+func NewEncryptedSecrets(path string, passphrase string) *EncryptedSecrets {
+	return &EncryptedSecrets{path: path, passphrase: passphrase}
+}
+
+// Get gets a secret from the encrypted file
+func (e *EncryptedSecrets) Get(name string) (string, error) {
+	// No file means no secrets
+	if !util.Exists(e.path) {
+		return "", nil
+	}
+	secrets, err := e.read()
+	if err != nil {
+		return "", err
+	}
+	return secrets[name], nil
+}
+
+// Set stores a secret in the encrypted file
+func (e *EncryptedSecrets) Set(name string, value string) error {
+	// Read the existing secrets
+	secrets := map[string]string{}
+	if util.Exists(e.path) {
+		var err error
+		secrets, err = e.read()
+		if err != nil {
+			return err
+		}
+	}
+	// Update and write the secrets
+	if value == "" {
+		delete(secrets, name)
+	} else {
+		secrets[name] = value
+	}
+	return e.write(secrets)
+}
+
+// read decrypts the secrets file
+func (e *EncryptedSecrets) read() (map[string]string, error) {
+	// Read the file
+	content, err := os.ReadFile(e.path)
+	if err != nil {
+		return nil, err
+	}
+	file := encryptedFile{}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", e.path, err)
+	}
+	salt, errSalt := base64.StdEncoding.DecodeString(file.Salt)
+	nonce, errNonce := base64.StdEncoding.DecodeString(file.Nonce)
+	sealed, errData := base64.StdEncoding.DecodeString(file.Data)
+	if errSalt != nil || errNonce != nil || errData != nil {
+		return nil, fmt.Errorf("invalid secrets file %s", e.path)
+	}
+	// Decrypt the data
+	gcm, err := e.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid secrets file %s", e.path)
+	}
+	data, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt %s, wrong passphrase?", e.path)
+	}
+	secrets := map[string]string{}
+	if err := yaml.Unmarshal(data, &secrets); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
+
+// write encrypts the secrets file with a fresh salt and nonce
+func (e *EncryptedSecrets) write(secrets map[string]string) error {
+	// Encode the secrets
+	data, err := yaml.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	// Encrypt the data
+	salt := make([]byte, secretSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := e.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	file := encryptedFile{
+		Salt:  base64.StdEncoding.EncodeToString(salt),
+		Nonce: base64.StdEncoding.EncodeToString(nonce),
+		Data:  base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, data, nil)),
+	}
+	// Write the file
+	content, err := yaml.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(e.path, content, secretFilePermission)
+}
+
+// cipher creates the AES-GCM cipher for a salt, asking for the passphrase when unset
+func (e *EncryptedSecrets) cipher(salt []byte) (cipher.AEAD, error) {
+	if e.passphrase == "" {
+		// Confirm the passphrase of a new file, as a typo would lock its secrets away
+		confirm := ""
+		if !util.Exists(e.path) {
+			confirm = "Confirm the secrets passphrase: "
+		}
+		passphrase, err := util.AskPassword("Enter the secrets passphrase: ", confirm)
+		if err != nil {
+			return nil, fmt.Errorf("set %s to unlock %s: %w", secretPassphraseEnv, e.path, err)
+		}
+		e.passphrase = passphrase
+	}
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(e.passphrase), salt, secretKeyIterations, secretKeyLength, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
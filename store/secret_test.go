@@ -0,0 +1,212 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestStore creates a store with a global file in a temporary home directory
+func newTestStore(t *testing.T, global string) (*BaseStore, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(secretPassphraseEnv, "")
+	t.Setenv(envPrefix+"OPENAI_API_KEY", "")
+	t.Setenv(openAIApiKeyEnv, "")
+	err := os.WriteFile(filepath.Join(home, storeDirName), []byte(global), storePermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBaseStore(""), home
+}
+
+func TestFileSecretsRoundTrip(t *testing.T) {
+	s, home := newTestStore(t, "vendor_name: Acme\n")
+	secrets := s.Secrets()
+	if err := secrets.Set(openAIApiKeySecret, "sk-file"); err != nil {
+		t.Fatal(err)
+	}
+	value, err := secrets.Get(openAIApiKeySecret)
+	if err != nil || value != "sk-file" {
+		t.Fatalf("got %q, %v, want sk-file", value, err)
+	}
+	// The key is kept in plain text in the global file
+	content, err := os.ReadFile(filepath.Join(home, storeDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "sk-file") {
+		t.Fatalf("key missing from the global file:\n%s", content)
+	}
+}
+
+func TestEnvSecretsRoundTrip(t *testing.T) {
+	s, _ := newTestStore(t, "secret_backend: env\n")
+	secrets := s.Secrets()
+	// Nothing is ever stored
+	if err := secrets.Set(openAIApiKeySecret, "sk-env"); err == nil {
+		t.Fatal("expected the env backend to refuse storing a secret")
+	}
+	t.Setenv(envPrefix+"OPENAI_API_KEY", "sk-env")
+	value, err := secrets.Get(openAIApiKeySecret)
+	if err != nil || value != "sk-env" {
+		t.Fatalf("got %q, %v, want sk-env", value, err)
+	}
+}
+
+func TestEncryptedSecretsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), secretsFileName)
+	if err := NewEncryptedSecrets(path, "passphrase").Set(openAIApiKeySecret, "sk-encrypted"); err != nil {
+		t.Fatal(err)
+	}
+	value, err := NewEncryptedSecrets(path, "passphrase").Get(openAIApiKeySecret)
+	if err != nil || value != "sk-encrypted" {
+		t.Fatalf("got %q, %v, want sk-encrypted", value, err)
+	}
+	// The file is private and never holds the key in plain text
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != secretFilePermission {
+		t.Fatalf("got permissions %v, want %v", info.Mode().Perm(), os.FileMode(secretFilePermission))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "sk-encrypted") {
+		t.Fatal("key stored in plain text")
+	}
+}
+
+func TestEncryptedSecretsWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), secretsFileName)
+	if err := NewEncryptedSecrets(path, "passphrase").Set(openAIApiKeySecret, "sk-encrypted"); err != nil {
+		t.Fatal(err)
+	}
+	value, err := NewEncryptedSecrets(path, "wrong").Get(openAIApiKeySecret)
+	if err == nil || value != "" {
+		t.Fatalf("got %q, %v, want a decryption error", value, err)
+	}
+	// A failed read must not replace the file
+	if err := NewEncryptedSecrets(path, "wrong").Set(openAIApiKeySecret, "sk-other"); err == nil {
+		t.Fatal("expected storing with the wrong passphrase to fail")
+	}
+	value, err = NewEncryptedSecrets(path, "passphrase").Get(openAIApiKeySecret)
+	if err != nil || value != "sk-encrypted" {
+		t.Fatalf("got %q, %v, want sk-encrypted", value, err)
+	}
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	s, home := newTestStore(t, "openai_api_key: sk-plain\nsecret_backend: encrypted\n")
+	t.Setenv(secretPassphraseEnv, "passphrase")
+	if _, err := s.RetrieveGlobal(); err != nil {
+		t.Fatal(err)
+	}
+	// The key left the global file for the encrypted one
+	content, err := os.ReadFile(filepath.Join(home, storeDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "sk-plain") {
+		t.Fatalf("key still in the global file:\n%s", content)
+	}
+	value, err := NewEncryptedSecrets(filepath.Join(home, secretsFileName), "passphrase").Get(openAIApiKeySecret)
+	if err != nil || value != "sk-plain" {
+		t.Fatalf("got %q, %v, want sk-plain", value, err)
+	}
+}
+
+func TestMigrateSecretsFromDefaultBackend(t *testing.T) {
+	s, home := newTestStore(t, "openai_api_key: sk-plain\n")
+	t.Setenv(secretPassphraseEnv, "passphrase")
+	global, err := s.RetrieveGlobal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A passphrase given up front moves the key and switches the backend
+	if global.SecretBackend != secretBackendCrypt {
+		t.Fatalf("got backend %q, want %q", global.SecretBackend, secretBackendCrypt)
+	}
+	content, err := os.ReadFile(filepath.Join(home, storeDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "sk-plain") || !strings.Contains(string(content), "secret_backend: encrypted") {
+		t.Fatalf("key not moved out of the global file:\n%s", content)
+	}
+	value, err := s.Secrets().Get(openAIApiKeySecret)
+	if err != nil || value != "sk-plain" {
+		t.Fatalf("got %q, %v, want sk-plain", value, err)
+	}
+}
+
+func TestMigrateSecretsWithoutPassphrase(t *testing.T) {
+	tests := []struct {
+		name   string
+		global string
+	}{
+		{name: "default backend", global: "openai_api_key: sk-plain\n"},
+		{name: "chosen file backend", global: "openai_api_key: sk-plain\nsecret_backend: file\n"},
+		{name: "encrypted backend", global: "openai_api_key: sk-plain\nsecret_backend: encrypted\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, home := newTestStore(t, test.global)
+			withoutTerminal(t)
+			if _, err := s.RetrieveGlobal(); err != nil {
+				t.Fatal(err)
+			}
+			// Nothing can be moved without a passphrase or a terminal to ask for one
+			content, err := os.ReadFile(filepath.Join(home, storeDirName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.global {
+				t.Fatalf("global file changed:\n%s", content)
+			}
+			if _, err := os.Stat(filepath.Join(home, secretsFileName)); !os.IsNotExist(err) {
+				t.Fatalf("secrets file created: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateSecretsKeepsChosenFileBackend(t *testing.T) {
+	s, home := newTestStore(t, "openai_api_key: sk-plain\nsecret_backend: file\n")
+	t.Setenv(secretPassphraseEnv, "passphrase")
+	if _, err := s.RetrieveGlobal(); err != nil {
+		t.Fatal(err)
+	}
+	// A file backend set on purpose keeps the key where it is
+	if _, err := os.Stat(filepath.Join(home, secretsFileName)); !os.IsNotExist(err) {
+		t.Fatalf("secrets file created: %v", err)
+	}
+	value, err := s.Secrets().Get(openAIApiKeySecret)
+	if err != nil || value != "sk-plain" {
+		t.Fatalf("got %q, %v, want sk-plain", value, err)
+	}
+}
+
+// withoutTerminal reads standard input from a regular file for the rest of the test
+func withoutTerminal(t *testing.T) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() {
+		os.Stdin = original
+		stdin.Close()
+	})
+}
@@ -16,37 +16,67 @@ const (
 // Store interface
 type Store interface {
 	CreateGlobal(force bool) error
-	CreateProject() error
+	CreateProject(name string, description string, apiVersion string) error
 	RetrieveGlobal() (*GlobalStore, error)
 	RetrieveProject() (*ProjectStore, error)
 	UpdateGlobal(store *GlobalStore) error
 	UpdateProject(store *ProjectStore) error
+	UseProject(name string) (*ProjectStore, error)
+	RenameProject(name string, newName string) (*ProjectStore, error)
+	RemoveProject(name string) (*ProjectStore, error)
+	ListConfig() ([]ConfigValue, error)
+	GetConfig(key string) (*ConfigValue, error)
+	SetConfig(key string, value string, global bool) error
 }
 
 type ProjectStore struct {
-	Current string `yaml:"current"`
+	Current   string            `yaml:"current"`
+	Projects  []*ProjectInfo    `yaml:"projects"`
+	Overrides map[string]string `yaml:"overrides,omitempty"`
+}
+
+type ProjectInfo struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Created     string `yaml:"created"`
+	ApiVersion  string `yaml:"api_version"`
 }
 
 type GlobalStore struct {
-	AuthorName   string `yaml:"author_name"`
-	AuthorEmail  string `yaml:"author_email"`
-	VendorName   string `yaml:"vendor_name"`
-	VendorPrefix string `yaml:"vendor_prefix"`
-	OpenAIApiKey string `yaml:"openai_api_key"`
+	AuthorName    string `yaml:"author_name"`
+	AuthorEmail   string `yaml:"author_email"`
+	VendorName    string `yaml:"vendor_name"`
+	VendorPrefix  string `yaml:"vendor_prefix"`
+	OpenAIApiKey  string `yaml:"openai_api_key"`
+	SecretBackend string `yaml:"secret_backend"`
+
+	InferenceProvider      string  `yaml:"inference_provider"`
+	InferenceModel         string  `yaml:"inference_model"`
+	InferenceBaseURL       string  `yaml:"inference_base_url"`
+	InferenceApiVersion    string  `yaml:"inference_api_version"`
+	InferenceTemperature   float64 `yaml:"inference_temperature"`
+	InferenceTimeout       int     `yaml:"inference_timeout"`
+	InferenceRetries       int     `yaml:"inference_retries"`
+	InferenceContextTokens int     `yaml:"inference_context_tokens"`
+	InferenceCache         string  `yaml:"inference_cache"`
 }
 
 type BaseStore struct {
+	// Project root directory
+	root string
+	// Configuration values set on the command line
+	flags map[string]string
 }
 
-// NewBaseStore creates a new BaseStore
-func NewBaseStore() *BaseStore {
-	return &BaseStore{}
+// NewBaseStore creates a new BaseStore for the project at root
+func NewBaseStore(root string) *BaseStore {
+	return &BaseStore{root: root}
 }
 
 // saveToFile saves content to a file
 func (s *BaseStore) saveToFile(path string, content interface{}) error {
 	// Open the file for writing
-	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, storePermissions)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, storePermissions)
 	if err != nil {
 		return err
 	}
@@ -75,6 +105,10 @@ func (s *BaseStore) readProjectFile(path string) (*ProjectStore, error) {
 	if err := yaml.NewDecoder(file).Decode(&store); err != nil {
 		return nil, err
 	}
+	// List the current project of stores written before projects were tracked
+	if store.Current != "" && store.Find(store.Current) == nil {
+		store.Projects = append(store.Projects, &ProjectInfo{Name: store.Current, ApiVersion: DefaultApiVersion})
+	}
 	return store, nil
 }
 
@@ -105,7 +139,7 @@ func (s *BaseStore) updateFile(path string, content interface{}) error {
 		return fmt.Errorf("store not found at %s, please run nsc init", path)
 	}
 	// Open the file for writing
-	file, err := os.OpenFile(path, os.O_WRONLY, storePermissions)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, storePermissions)
 	if err != nil {
 		return err
 	}
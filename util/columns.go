@@ -0,0 +1,55 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Terminal width used when COLUMNS is not set
+const defaultColumns = 160
+
+// SideBySide lays out two texts in titled columns that share the terminal width,
+// wrapping lines that do not fit
+func SideBySide(leftTitle string, left string, rightTitle string, right string) string {
+	// Split the terminal width between both columns and the separator
+	columns, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || columns < 40 {
+		columns = defaultColumns
+	}
+	width := (columns - 3) / 2
+
+	// Wrap both texts to the column width
+	leftLines := append([]string{leftTitle, strings.Repeat("-", width)}, wrapLines(left, width)...)
+	rightLines := append([]string{rightTitle, strings.Repeat("-", width)}, wrapLines(right, width)...)
+
+	// Join the lines of both columns
+	var result strings.Builder
+	for i := 0; i < len(leftLines) || i < len(rightLines); i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		result.WriteString(strings.TrimRight(fmt.Sprintf("%-*s | %s", width, l, r), " "))
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// wrapLines splits text into lines no longer than width, expanding tabs
+func wrapLines(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		runes := []rune(strings.ReplaceAll(line, "\t", "    "))
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
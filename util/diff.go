@@ -0,0 +1,132 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is a line of a diff with its kind: ' ', '-' or '+'
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the unified diff between two texts, labelled with the file name
+func UnifiedDiff(name string, before string, after string) string {
+	if before == after {
+		return ""
+	}
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", name, name))
+	// Walk the lines, grouping changes closer than twice the context into hunks
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			i++
+			oldLine++
+			newLine++
+			continue
+		}
+		// Start the hunk a few lines before the change
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		// Extend the hunk while changes keep coming within the context
+		end, unchanged := i, 0
+		for end < len(lines) && unchanged <= 2*diffContext {
+			if lines[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		end -= unchanged
+		if unchanged > diffContext {
+			unchanged = diffContext
+		}
+		end += unchanged
+		// Count the lines of each side and write the hunk
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, line := range lines[start:end] {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+			body.WriteString(fmt.Sprintf("%c%s\n", line.kind, line.text))
+		}
+		b.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", hunkOld, oldCount, hunkNew, newCount))
+		b.WriteString(body.String())
+		// Move past the hunk
+		for _, line := range lines[i:end] {
+			if line.kind != '+' {
+				oldLine++
+			}
+			if line.kind != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// splitLines splits a text into lines without the trailing newline
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines returns the edit script between two line lists from their longest common subsequence
+func diffLines(a []string, b []string) []diffLine {
+	// Build the table of common subsequence lengths from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	// Walk the table, preferring removals before additions
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
@@ -16,5 +16,8 @@ func GetOptions() []string {
 		"workflowaction: Workflow action scripts are good for custom logic or managing sublist fields which are not currently available",
 		"module: A module is a container for scripts, providing a way to organize and manage your code",
 		"type: Holds TypeScript definitions for your scripts, providing a way to define the structure and types of your code",
+		"field: Custom body, column, entity and item fields extend standard records, with their ids kept in a fields.ts module",
+		"param: Script parameters are added to a script object with a typed getParams() accessor in its script file",
+		"record: Custom record types store your own data in NetSuite, generated with a typed TypeScript accessor for their fields",
 	}
 }
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// PrintTable prints rows as aligned columns under the given headers
+func PrintTable(headers []string, rows [][]string) {
+	// Create a tab writer on the standard output
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	// Write the headers followed by each row
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	// Flush the aligned output
+	w.Flush()
+}
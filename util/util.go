@@ -3,7 +3,10 @@ package util
 import (
 	"bufio"
 	"fmt"
+	"golang.org/x/term"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +23,17 @@ func Exists(path string) bool {
 
 // GetInput gets user input
 func GetInput(msg string) string {
+	// Read the input and ignore errors
+	input, err := readInput(msg)
+	if err != nil {
+		// If there is an error, return an empty string
+		return ""
+	}
+	return input
+}
+
+// readInput prints msg and reads a line from the standard input
+func readInput(msg string) (string, error) {
 	// Print the message to the console
 	fmt.Println(msg)
 	// Create a new reader for the standard input
@@ -27,9 +41,188 @@ func GetInput(msg string) string {
 	// Read a line of input from the user
 	input, err := reader.ReadString('\n')
 	if err != nil {
-		// If there is an error, return an empty string
-		return ""
+		return "", err
 	}
 	// Remove the newline character from the end of the input
-	return strings.Replace(strings.TrimSuffix(input, "\n"), "\r", "", -1)
+	return strings.Replace(strings.TrimSuffix(input, "\n"), "\r", "", -1), nil
+}
+
+// readPassword prints a message and reads a line from the terminal without echoing it
+func readPassword(msg string) (string, error) {
+	// Print the message to the console
+	fmt.Println(msg)
+	// Read the input with echo turned off
+	input, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	return string(input), nil
+}
+
+// AskPassword prompts the user with msg for a secret without echoing it. When
+// confirmMsg is set the secret is asked for twice and both must match.
+func AskPassword(msg string, confirmMsg string) (string, error) {
+	// Never block on a prompt that cannot be answered
+	if !IsInteractive() {
+		return "", fmt.Errorf("stdin is not a terminal")
+	}
+	for {
+		// Ask until a value is given
+		value, err := readPassword(msg)
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			continue
+		}
+		if confirmMsg == "" {
+			return value, nil
+		}
+		// Ask again until both values match
+		again, err := readPassword(confirmMsg)
+		if err != nil {
+			return "", err
+		}
+		if again == value {
+			return value, nil
+		}
+		fmt.Println("The values do not match, try again")
+	}
+}
+
+// IsInteractive checks if the standard input is a terminal
+func IsInteractive() bool {
+	// Get the standard input file information
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		// If the information is unavailable, assume no terminal
+		return false
+	}
+	// A terminal is a character device
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// The null device is a character device too, but never answers
+	null, err := os.Stat(os.DevNull)
+	if err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// AskInput returns value when set, otherwise prompts the user with msg.
+// When yes is set the fallback is used without prompting, and when the
+// standard input is not a terminal an error naming flag is returned.
+func AskInput(value string, msg string, flag string, fallback string, yes bool) (string, error) {
+	// Use the supplied value when present
+	if value != "" {
+		return value, nil
+	}
+	// Use the fallback when prompting was declined
+	if yes {
+		if fallback == "" {
+			return "", fmt.Errorf("--%s must be set when running with --yes", flag)
+		}
+		return fallback, nil
+	}
+	// Never block on a prompt that cannot be answered
+	if !IsInteractive() {
+		return "", fmt.Errorf("--%s must be set when stdin is not a terminal", flag)
+	}
+	// Ask until a value is given, unless a fallback exists
+	for value == "" {
+		input, err := readInput(msg)
+		if err != nil {
+			return "", err
+		}
+		if input == "" && fallback != "" {
+			return fallback, nil
+		}
+		value = input
+	}
+	return value, nil
+}
+
+// Confirm asks the user a yes or no question, answering yes when yes is set.
+// An error is returned when the standard input is not a terminal.
+func Confirm(msg string, yes bool) (bool, error) {
+	// Skip the question when confirmation was given up front
+	if yes {
+		return true, nil
+	}
+	// Never block on a prompt that cannot be answered
+	if !IsInteractive() {
+		return false, fmt.Errorf("--yes must be set when stdin is not a terminal")
+	}
+	// Ask the question, defaulting to no
+	input, err := readInput(msg + " (y/N): ")
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// MultiSelect asks the user to pick options by number or name, separated by
+// commas or spaces. An empty answer picks every option.
+func MultiSelect(msg string, options []string) ([]string, error) {
+	// List the options
+	fmt.Println(msg)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	// Ask until every answer names an option
+	for {
+		input, err := readInput("Enter numbers or names, empty for all: ")
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(fields) == 0 {
+			return options, nil
+		}
+		var picked []string
+		for _, field := range fields {
+			if n, err := strconv.Atoi(field); err == nil && n >= 1 && n <= len(options) {
+				field = options[n-1]
+			}
+			if !slices.Contains(options, field) {
+				fmt.Printf("Unknown option %q\n", field)
+				picked = nil
+				break
+			}
+			if !slices.Contains(picked, field) {
+				picked = append(picked, field)
+			}
+		}
+		if picked != nil {
+			return picked, nil
+		}
+	}
+}
+
+// Choose asks the user to pick one option by number or name until a valid one is given
+func Choose(msg string, options []string) (string, error) {
+	// List the options
+	fmt.Println(msg)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	for {
+		input, err := readInput("Enter a number or name: ")
+		if err != nil {
+			return "", err
+		}
+		input = strings.TrimSpace(input)
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if slices.Contains(options, input) {
+			return input, nil
+		}
+		fmt.Printf("Unknown option %q\n", input)
+	}
 }